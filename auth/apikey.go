@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/hexaforce/swagger-echo/model"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ErrAPIKeyExpired is returned for stored API keys past their expiry.
+var ErrAPIKeyExpired error = &statusError{"API key is expired", http.StatusUnauthorized}
+
+// apiKeyPrefix marks the keys generated by GenerateAPIKey.
+const apiKeyPrefix = "cel_"
+
+// GenerateAPIKey returns a new random API key, the prefix it is shown by
+// after creation and the hash it is stored under.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:len(apiKeyPrefix)+6], HashAPIKey(key), nil
+}
+
+// HashAPIKey is the hash an API key is stored and looked up by.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyStore authenticates the API keys of a model.APIKeyStore and records
+// when each key was last used.
+type KeyStore struct {
+	keys model.APIKeyStore
+	now  func() time.Time
+}
+
+// NewKeyStore returns a KeyStore backed by keys.
+func NewKeyStore(keys model.APIKeyStore) *KeyStore {
+	return &KeyStore{keys: keys, now: time.Now}
+}
+
+// AdminByAPIKey implements APIKeyStore. The admin carries the key's name,
+// scopes and the roles it was created with.
+func (s *KeyStore) AdminByAPIKey(key string) (model.Admin, error) {
+	k, err := s.keys.APIKeyByHash(HashAPIKey(key))
+	if err == model.ErrNoRow {
+		return model.Admin{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.Admin{}, err
+	}
+	now := s.now().UTC()
+	if k.Expired(now) {
+		return model.Admin{}, ErrAPIKeyExpired
+	}
+	if err := s.keys.TouchAPIKey(k.ID, now); err != nil {
+		return model.Admin{}, err
+	}
+	return model.Admin{ID: k.AdminID, Name: k.Name, Roles: k.Roles, Scopes: k.Scopes, KeyID: k.ID.String(), KeyPrefix: k.Prefix}, nil
+}
+
+// APIKeyValid implements token.KeyStore: the key id must still exist with
+// the given prefix, which changes when the key is rotated, and not be
+// expired.
+func (s *KeyStore) APIKeyValid(id, prefix string) (bool, error) {
+	u, err := uuid.FromString(id)
+	if err != nil {
+		return false, nil
+	}
+	k, err := s.keys.APIKeyOne(u)
+	if err == model.ErrNoRow {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return k.Prefix == prefix && !k.Expired(s.now().UTC()), nil
+}
+
+// APIKeyStores tries each API key store in turn.
+type APIKeyStores []APIKeyStore
+
+// AdminByAPIKey implements APIKeyStore. When no store accepts the key the
+// first error other than ErrInvalidCredentials is returned, as it says more.
+func (ks APIKeyStores) AdminByAPIKey(key string) (model.Admin, error) {
+	err := ErrInvalidCredentials
+	for _, s := range ks {
+		admin, serr := s.AdminByAPIKey(key)
+		if serr == nil {
+			return admin, nil
+		}
+		if err == ErrInvalidCredentials {
+			err = serr
+		}
+	}
+	return model.Admin{}, err
+}
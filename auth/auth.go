@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/labstack/echo"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials.
+	ErrNoCredentials error = &statusError{"Authorization header is required", http.StatusUnauthorized}
+	// ErrInvalidCredentials is returned for credentials no backend accepts.
+	ErrInvalidCredentials error = &statusError{"credentials are invalid", http.StatusUnauthorized}
+)
+
+// statusError is an error answered with the HTTP status it carries.
+type statusError struct {
+	msg    string
+	status int
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// Status is the HTTP status the error is answered with.
+func (e *statusError) Status() int {
+	return e.status
+}
+
+// APIKeyStore resolves API keys to admins.
+type APIKeyStore interface {
+	AdminByAPIKey(key string) (model.Admin, error)
+}
+
+// PasswordStore resolves Basic credentials to admins.
+type PasswordStore interface {
+	AdminByPassword(name, password string) (model.Admin, error)
+}
+
+// TokenStore resolves bearer tokens to admins.
+type TokenStore interface {
+	AdminByToken(token string) (model.Admin, error)
+}
+
+// TokenStores tries each token store in turn.
+type TokenStores []TokenStore
+
+// AdminByToken implements TokenStore. When no store accepts the token the
+// first error other than ErrInvalidCredentials is returned, as it says more.
+func (ts TokenStores) AdminByToken(token string) (model.Admin, error) {
+	err := ErrInvalidCredentials
+	for _, s := range ts {
+		admin, serr := s.AdminByToken(token)
+		if serr == nil {
+			return admin, nil
+		}
+		if err == ErrInvalidCredentials {
+			err = serr
+		}
+	}
+	return model.Admin{}, err
+}
+
+// Config selects the credential backends of the middleware.
+// A nil backend rejects the credentials it would check.
+type Config struct {
+	APIKeys   APIKeyStore
+	Passwords PasswordStore
+	Tokens    TokenStore
+	// Realm is sent in the WWW-Authenticate header of rejected requests.
+	Realm string
+}
+
+// contextKey is the echo context key the authenticated admin is stored under.
+const contextKey = "auth.admin"
+
+// Middleware authenticates the Authorization header of every request.
+// "Basic" credentials are checked against cfg.Passwords, "Bearer" tokens
+// against cfg.Tokens and any other value is taken as an API key.
+// The authenticated admin is put on the context, see Admin.
+func Middleware(cfg Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			admin, err := cfg.authenticate(ctx.Request().Header.Get(echo.HeaderAuthorization))
+			if err != nil {
+				ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="`+cfg.realm()+`"`)
+				return err
+			}
+			SetAdmin(ctx, admin)
+			return next(ctx)
+		}
+	}
+}
+
+func (cfg Config) realm() string {
+	if cfg.Realm == "" {
+		return "celler"
+	}
+	return cfg.Realm
+}
+
+func (cfg Config) authenticate(header string) (model.Admin, error) {
+	if header == "" {
+		return model.Admin{}, ErrNoCredentials
+	}
+	scheme, credentials := splitAuthorization(header)
+	switch {
+	case strings.EqualFold(scheme, "Basic"):
+		return cfg.basic(credentials)
+	case strings.EqualFold(scheme, "Bearer"):
+		return cfg.bearer(credentials)
+	default:
+		return cfg.apiKey(header)
+	}
+}
+
+// splitAuthorization splits an Authorization header into its scheme and credentials.
+func splitAuthorization(header string) (string, string) {
+	if i := strings.IndexByte(header, ' '); i >= 0 {
+		return header[:i], strings.TrimSpace(header[i+1:])
+	}
+	return header, ""
+}
+
+func (cfg Config) basic(credentials string) (model.Admin, error) {
+	if cfg.Passwords == nil {
+		return model.Admin{}, ErrInvalidCredentials
+	}
+	b, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return model.Admin{}, ErrInvalidCredentials
+	}
+	kv := strings.SplitN(string(b), ":", 2)
+	if len(kv) != 2 {
+		return model.Admin{}, ErrInvalidCredentials
+	}
+	return cfg.Passwords.AdminByPassword(kv[0], kv[1])
+}
+
+func (cfg Config) bearer(token string) (model.Admin, error) {
+	if cfg.Tokens == nil {
+		return model.Admin{}, ErrInvalidCredentials
+	}
+	return cfg.Tokens.AdminByToken(token)
+}
+
+func (cfg Config) apiKey(key string) (model.Admin, error) {
+	if cfg.APIKeys == nil {
+		return model.Admin{}, ErrInvalidCredentials
+	}
+	return cfg.APIKeys.AdminByAPIKey(key)
+}
+
+// SetAdmin puts the authenticated admin on the context.
+func SetAdmin(ctx echo.Context, admin model.Admin) {
+	ctx.Set(contextKey, admin)
+}
+
+// Admin returns the admin authenticated by Middleware, if any.
+func Admin(ctx echo.Context) (model.Admin, bool) {
+	admin, ok := ctx.Get(contextKey).(model.Admin)
+	return admin, ok
+}
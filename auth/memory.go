@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"sync"
+
+	"github.com/hexaforce/swagger-echo/model"
+)
+
+// MemoryStore keeps API keys, passwords and bearer tokens in process memory.
+// Secrets are kept as SHA-256 hashes. It is safe for concurrent use.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	apiKeys   map[[sha256.Size]byte]model.Admin
+	passwords map[string]password
+	tokens    map[[sha256.Size]byte]model.Admin
+}
+
+type password struct {
+	hash  [sha256.Size]byte
+	admin model.Admin
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		apiKeys:   map[[sha256.Size]byte]model.Admin{},
+		passwords: map[string]password{},
+		tokens:    map[[sha256.Size]byte]model.Admin{},
+	}
+}
+
+// AddAPIKey lets key authenticate as admin.
+func (s *MemoryStore) AddAPIKey(key string, admin model.Admin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKeys[sha256.Sum256([]byte(key))] = admin
+}
+
+// AddPassword lets admin authenticate with its name and password.
+func (s *MemoryStore) AddPassword(pw string, admin model.Admin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passwords[admin.Name] = password{hash: sha256.Sum256([]byte(pw)), admin: admin}
+}
+
+// AddToken lets the bearer token authenticate as admin.
+func (s *MemoryStore) AddToken(token string, admin model.Admin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[sha256.Sum256([]byte(token))] = admin
+}
+
+// AdminByAPIKey implements APIKeyStore.
+func (s *MemoryStore) AdminByAPIKey(key string) (model.Admin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	admin, ok := s.apiKeys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return model.Admin{}, ErrInvalidCredentials
+	}
+	return admin, nil
+}
+
+// AdminByPassword implements PasswordStore.
+func (s *MemoryStore) AdminByPassword(name, pw string) (model.Admin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.passwords[name]
+	sum := sha256.Sum256([]byte(pw))
+	if !ok || subtle.ConstantTimeCompare(p.hash[:], sum[:]) != 1 {
+		return model.Admin{}, ErrInvalidCredentials
+	}
+	return p.admin, nil
+}
+
+// AdminByToken implements TokenStore.
+func (s *MemoryStore) AdminByToken(token string) (model.Admin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	admin, ok := s.tokens[sha256.Sum256([]byte(token))]
+	if !ok {
+		return model.Admin{}, ErrInvalidCredentials
+	}
+	return admin, nil
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/spec"
+	"github.com/labstack/echo"
+)
+
+// ErrInsufficientScope is returned when the caller is authenticated but lacks a required scope.
+var ErrInsufficientScope error = &statusError{"credentials lack a required scope", http.StatusForbidden}
+
+// Requirement is one security requirement of an operation: every named
+// scheme must be satisfied, OAuth2 schemes with all the listed scopes.
+type Requirement = spec.SecurityRequirement
+
+// Spec holds the security requirements of the operations of a swagger document.
+type Spec struct {
+	doc *spec.Document
+}
+
+// LoadSpec reads the security requirements from the document registered with swag.
+func LoadSpec() (*Spec, error) {
+	b, err := spec.Read("")
+	if err != nil {
+		return nil, err
+	}
+	doc, err := spec.Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	return NewSpec(doc)
+}
+
+// NewSpec returns the security requirements of the operations of doc. Every
+// scheme a requirement names must be defined.
+func NewSpec(doc *spec.Document) (*Spec, error) {
+	for _, op := range doc.Operations() {
+		for _, r := range op.Security {
+			for name := range r {
+				if _, ok := doc.SecurityDefinitions[name]; !ok {
+					return nil, errors.New("security scheme " + name + " is not defined")
+				}
+			}
+		}
+	}
+	return &Spec{doc: doc}, nil
+}
+
+// Requirements returns the security requirements of the operation echo routed
+// method and path to. Operations without any are public.
+func (s *Spec) Requirements(method, path string) []Requirement {
+	if op := s.doc.Operation(method, path); op != nil {
+		return op.Security
+	}
+	return nil
+}
+
+// Enforce checks every request against the security requirements the spec
+// declares for the matched route. The caller must satisfy at least one
+// requirement; missing or invalid credentials fail with the error of the
+// credential check, which the error handler answers with a 401, and missing
+// OAuth2 scopes with ErrInsufficientScope, a 403. The authenticated admin is
+// put on the context, see Admin.
+func Enforce(cfg Config, s *Spec) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			reqs := s.Requirements(ctx.Request().Method, ctx.Path())
+			if len(reqs) == 0 {
+				return next(ctx)
+			}
+			c := &check{cfg: cfg, spec: s, req: ctx.Request(), admins: map[string]result{}}
+			err := ErrNoCredentials
+			for _, r := range reqs {
+				admin, rerr := c.satisfy(r)
+				if rerr == nil {
+					SetAdmin(ctx, admin)
+					return next(ctx)
+				}
+				// Report the most specific failure: a caller that authenticated
+				// but lacks scopes is forbidden rather than unauthorized.
+				if rerr == ErrInsufficientScope || err == ErrNoCredentials {
+					err = rerr
+				}
+			}
+			if challenge := c.challenge(reqs); challenge != "" && err != ErrInsufficientScope {
+				ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+			}
+			return err
+		}
+	}
+}
+
+type result struct {
+	admin model.Admin
+	err   error
+}
+
+// check authenticates one request, each scheme at most once.
+type check struct {
+	cfg    Config
+	spec   *Spec
+	req    *http.Request
+	admins map[string]result
+}
+
+// satisfy checks every scheme of r and returns the admin authenticated by the last one.
+func (c *check) satisfy(r Requirement) (model.Admin, error) {
+	var admin model.Admin
+	for name, scopes := range r {
+		res := c.authenticate(name)
+		if res.err != nil {
+			return model.Admin{}, res.err
+		}
+		for _, scope := range scopes {
+			if !hasScope(res.admin, scope) {
+				return model.Admin{}, ErrInsufficientScope
+			}
+		}
+		admin = res.admin
+	}
+	return admin, nil
+}
+
+func (c *check) authenticate(name string) result {
+	if res, ok := c.admins[name]; ok {
+		return res
+	}
+	var res result
+	scheme := c.spec.doc.SecurityDefinitions[name]
+	header := c.req.Header.Get(echo.HeaderAuthorization)
+	kind, credentials := splitAuthorization(header)
+	switch scheme.Type {
+	case "basic":
+		if strings.EqualFold(kind, "Basic") {
+			res.admin, res.err = c.cfg.basic(credentials)
+		} else {
+			res.err = ErrNoCredentials
+		}
+	case "oauth2":
+		if strings.EqualFold(kind, "Bearer") {
+			res.admin, res.err = c.cfg.bearer(credentials)
+		} else {
+			res.err = ErrNoCredentials
+		}
+	case "apiKey":
+		var key string
+		if scheme.In == "query" {
+			key = c.req.URL.Query().Get(scheme.Name)
+		} else {
+			key = c.req.Header.Get(scheme.Name)
+		}
+		if key == "" {
+			res.err = ErrNoCredentials
+		} else {
+			res.admin, res.err = c.cfg.apiKey(key)
+		}
+	default:
+		res.err = ErrInvalidCredentials
+	}
+	c.admins[name] = res
+	return res
+}
+
+// challenge lists the HTTP authentication schemes accepted by reqs for WWW-Authenticate.
+func (c *check) challenge(reqs []Requirement) string {
+	var schemes []string
+	for _, r := range reqs {
+		for name := range r {
+			var s string
+			switch c.spec.doc.SecurityDefinitions[name].Type {
+			case "basic":
+				s = `Basic realm="` + c.cfg.realm() + `"`
+			case "oauth2":
+				s = `Bearer realm="` + c.cfg.realm() + `"`
+			default:
+				continue
+			}
+			if !containsString(schemes, s) {
+				schemes = append(schemes, s)
+			}
+		}
+	}
+	return strings.Join(schemes, ", ")
+}
+
+// hasScope reports whether admin holds scope. The admin scope grants read
+// and write, as its description in the security definitions says.
+func hasScope(admin model.Admin, scope string) bool {
+	if (scope == "read" || scope == "write") && containsString(admin.Scopes, "admin") {
+		return true
+	}
+	return containsString(admin.Scopes, scope)
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrNotExist is returned when no blob is stored under a key.
+	ErrNotExist error = &statusError{"blob does not exist", http.StatusNotFound}
+	// ErrKeyInvalid is returned for keys that are empty or escape the store.
+	ErrKeyInvalid = errors.New("blob key is invalid")
+)
+
+// statusError is an error answered with the HTTP status it carries.
+type statusError struct {
+	msg    string
+	status int
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// Status is the HTTP status the error is answered with.
+func (e *statusError) Status() int {
+	return e.status
+}
+
+// File is an open blob. It supports seeking so that it can serve range requests.
+type File interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// Store keeps binary objects under slash separated keys.
+type Store interface {
+	// Put stores the content of r under key, replacing any previous blob,
+	// and returns the number of bytes written.
+	Put(key string, r io.Reader) (int64, error)
+	// Open opens the blob stored under key.
+	Open(key string) (File, error)
+	// Delete removes the blob stored under key.
+	Delete(key string) error
+}
@@ -0,0 +1,88 @@
+package blob
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps blobs as files below a directory.
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore returns a LocalStore rooted at dir, creating it if needed.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean != "/"+key || strings.HasSuffix(key, "/") {
+		return "", ErrKeyInvalid
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean[1:])), nil
+}
+
+// Put writes the blob to a temporary file and renames it into place,
+// so readers never see a partially written blob.
+func (s *LocalStore) Put(key string, r io.Reader) (int64, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return 0, err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".upload-")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return 0, err
+	}
+	return n, nil
+}
+
+// Open opens the file stored under key.
+func (s *LocalStore) Open(key string) (File, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Delete removes the file stored under key.
+func (s *LocalStore) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if os.IsNotExist(err) {
+		return ErrNotExist
+	}
+	return err
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hexaforce/swagger-echo/model"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AccountQuery selects the accounts ListAccounts returns.
+type AccountQuery struct {
+	// Q searches the account names.
+	Q string
+	// Filter is an expression over id, name, uuid and owner_id, e.g.
+	// name sw "a" and owner_id eq 1.
+	Filter string
+	model.ListOptions
+}
+
+// ShowAccount returns the account with the ID or UUID id.
+func (c *Client) ShowAccount(ctx context.Context, id string) (model.Account, error) {
+	var account model.Account
+	r, err := jsonRequest(http.MethodGet, "/accounts/"+url.PathEscape(id), nil, nil)
+	if err != nil {
+		return account, err
+	}
+	_, err = c.do(ctx, r, &account)
+	return account, err
+}
+
+// ListAccounts returns the accounts selected by q and the page they are on.
+func (c *Client) ListAccounts(ctx context.Context, q AccountQuery) ([]model.Account, model.Page, error) {
+	query := listQuery(q.ListOptions)
+	if q.Q != "" {
+		query.Set("q", q.Q)
+	}
+	if q.Filter != "" {
+		query.Set("filter", q.Filter)
+	}
+	var accounts []model.Account
+	r, err := jsonRequest(http.MethodGet, "/accounts", query, nil)
+	if err != nil {
+		return nil, model.Page{}, err
+	}
+	h, err := c.do(ctx, r, &accounts)
+	if err != nil {
+		return nil, model.Page{}, err
+	}
+	return accounts, pageOf(h), nil
+}
+
+// AddAccount creates an account.
+func (c *Client) AddAccount(ctx context.Context, add model.AddAccount) (model.Account, error) {
+	var account model.Account
+	r, err := jsonRequest(http.MethodPost, "/accounts", nil, add)
+	if err != nil {
+		return account, err
+	}
+	_, err = c.do(ctx, r, &account)
+	return account, err
+}
+
+// UpdateAccount renames the account id.
+func (c *Client) UpdateAccount(ctx context.Context, id int, update model.UpdateAccount) (model.Account, error) {
+	var account model.Account
+	r, err := jsonRequest(http.MethodPatch, "/accounts/"+strconv.Itoa(id), nil, update)
+	if err != nil {
+		return account, err
+	}
+	_, err = c.do(ctx, r, &account)
+	return account, err
+}
+
+// DeleteAccount deletes the account id.
+func (c *Client) DeleteAccount(ctx context.Context, id int) error {
+	r, err := jsonRequest(http.MethodDelete, "/accounts/"+strconv.Itoa(id), nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, r, nil)
+	return err
+}
+
+// UploadAccountImage uploads the image read from image as filename to the
+// account id. The image is read before it is sent, so that it can be sent
+// again on retries.
+func (c *Client) UploadAccountImage(ctx context.Context, id int, filename string, image io.Reader) (model.Image, error) {
+	var uploaded model.Image
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return uploaded, err
+	}
+	if _, err := io.Copy(part, image); err != nil {
+		return uploaded, err
+	}
+	if err := w.Close(); err != nil {
+		return uploaded, err
+	}
+	r := &request{
+		method:      http.MethodPost,
+		path:        "/accounts/" + strconv.Itoa(id) + "/images",
+		contentType: w.FormDataContentType(),
+		body:        body.Bytes(),
+		auth:        true,
+	}
+	_, err = c.do(ctx, r, &uploaded)
+	return uploaded, err
+}
+
+// ListAccountImages returns the images of the account id.
+func (c *Client) ListAccountImages(ctx context.Context, id int) ([]model.Image, error) {
+	var images []model.Image
+	r, err := jsonRequest(http.MethodGet, "/accounts/"+strconv.Itoa(id)+"/images", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.do(ctx, r, &images)
+	return images, err
+}
+
+// ShowAccountImage returns the content of an image of the account id, or
+// of its thumbnail size when size is not empty. The caller closes it.
+func (c *Client) ShowAccountImage(ctx context.Context, id int, imageID uuid.UUID, size string) (io.ReadCloser, error) {
+	query := url.Values{}
+	if size != "" {
+		query.Set("size", size)
+	}
+	r, err := jsonRequest(http.MethodGet, "/accounts/"+strconv.Itoa(id)+"/images/"+imageID.String(), query, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// DeleteAccountImage deletes an image of the account id.
+func (c *Client) DeleteAccountImage(ctx context.Context, id int, imageID uuid.UUID) error {
+	r, err := jsonRequest(http.MethodDelete, "/accounts/"+strconv.Itoa(id)+"/images/"+imageID.String(), nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, r, nil)
+	return err
+}
+
+// ListAccountBottles returns the bottles of the account id and the page
+// they are on.
+func (c *Client) ListAccountBottles(ctx context.Context, id int, opts model.ListOptions) ([]model.Bottle, model.Page, error) {
+	var bottles []model.Bottle
+	r, err := jsonRequest(http.MethodGet, "/accounts/"+strconv.Itoa(id)+"/bottles", listQuery(opts), nil)
+	if err != nil {
+		return nil, model.Page{}, err
+	}
+	h, err := c.do(ctx, r, &bottles)
+	if err != nil {
+		return nil, model.Page{}, err
+	}
+	return bottles, pageOf(h), nil
+}
+
+// AddAccountBottle creates a bottle of the account id.
+func (c *Client) AddAccountBottle(ctx context.Context, id int, add model.AddAccountBottle) (model.Bottle, error) {
+	var bottle model.Bottle
+	r, err := jsonRequest(http.MethodPost, "/accounts/"+strconv.Itoa(id)+"/bottles", nil, add)
+	if err != nil {
+		return bottle, err
+	}
+	_, err = c.do(ctx, r, &bottle)
+	return bottle, err
+}
+
+// ShowAccountBottle returns a bottle of the account id.
+func (c *Client) ShowAccountBottle(ctx context.Context, id, bottleID int) (model.Bottle, error) {
+	var bottle model.Bottle
+	r, err := jsonRequest(http.MethodGet, "/accounts/"+strconv.Itoa(id)+"/bottles/"+strconv.Itoa(bottleID), nil, nil)
+	if err != nil {
+		return bottle, err
+	}
+	_, err = c.do(ctx, r, &bottle)
+	return bottle, err
+}
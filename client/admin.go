@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/spec"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Auth issues a JWT access and refresh token for the admin authenticated by
+// the API key or Basic credentials of the client. Use the access token with
+// Bearer.
+func (c *Client) Auth(ctx context.Context) (model.AdminToken, error) {
+	var t model.AdminToken
+	r, err := jsonRequest(http.MethodPost, "/admin/auth", nil, nil)
+	if err != nil {
+		return t, err
+	}
+	_, err = c.do(ctx, r, &t)
+	return t, err
+}
+
+// RefreshAuth exchanges a refresh token for a new token pair.
+func (c *Client) RefreshAuth(ctx context.Context, refreshToken string) (model.AdminToken, error) {
+	var t model.AdminToken
+	r, err := jsonRequest(http.MethodPost, "/admin/refresh", nil, model.RefreshToken{RefreshToken: refreshToken})
+	if err != nil {
+		return t, err
+	}
+	r.auth = false
+	_, err = c.do(ctx, r, &t)
+	return t, err
+}
+
+// RevokeAuth revokes an access or refresh token.
+func (c *Client) RevokeAuth(ctx context.Context, token string) error {
+	r, err := jsonRequest(http.MethodPost, "/admin/revoke", nil, model.RevokeToken{Token: token})
+	if err != nil {
+		return err
+	}
+	r.auth = false
+	_, err = c.do(ctx, r, nil)
+	return err
+}
+
+// ListAPIKeys returns the API keys without their secrets.
+func (c *Client) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	r, err := jsonRequest(http.MethodGet, "/admin/keys", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.do(ctx, r, &keys)
+	return keys, err
+}
+
+// AddAPIKey creates an API key; its secret is only returned here.
+func (c *Client) AddAPIKey(ctx context.Context, add model.AddAPIKey) (model.NewAPIKey, error) {
+	var key model.NewAPIKey
+	r, err := jsonRequest(http.MethodPost, "/admin/keys", nil, add)
+	if err != nil {
+		return key, err
+	}
+	_, err = c.do(ctx, r, &key)
+	return key, err
+}
+
+// RotateAPIKey replaces the secret of the API key id.
+func (c *Client) RotateAPIKey(ctx context.Context, id uuid.UUID) (model.NewAPIKey, error) {
+	var key model.NewAPIKey
+	r, err := jsonRequest(http.MethodPost, "/admin/keys/"+id.String()+"/rotate", nil, nil)
+	if err != nil {
+		return key, err
+	}
+	_, err = c.do(ctx, r, &key)
+	return key, err
+}
+
+// RevokeAPIKey deletes the API key id.
+func (c *Client) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	r, err := jsonRequest(http.MethodDelete, "/admin/keys/"+id.String(), nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, r, nil)
+	return err
+}
+
+// ShowDrift compares the routes of the server with its spec.
+func (c *Client) ShowDrift(ctx context.Context) (*spec.Drift, error) {
+	var drift spec.Drift
+	r, err := jsonRequest(http.MethodGet, "/admin/drift", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.do(ctx, r, &drift); err != nil {
+		return nil, err
+	}
+	return &drift, nil
+}
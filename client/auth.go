@@ -0,0 +1,124 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hexaforce/swagger-echo/oauth"
+	"github.com/labstack/echo"
+)
+
+// Authenticator adds credentials to a request.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// APIKey authenticates with an API key in the Authorization header, the
+// ApiKeyAuth requirement of the spec.
+type APIKey string
+
+// Authenticate sets the Authorization header.
+func (k APIKey) Authenticate(req *http.Request) error {
+	req.Header.Set(echo.HeaderAuthorization, string(k))
+	return nil
+}
+
+// Basic authenticates with the password of a user, the BasicAuth
+// requirement of the spec.
+type Basic struct {
+	Username string
+	Password string
+}
+
+// Authenticate sets the Basic credentials.
+func (b Basic) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(b.Username, b.Password)
+	return nil
+}
+
+// Bearer authenticates with an access token, a JWT issued by Client.Auth or
+// an OAuth2 access token.
+type Bearer string
+
+// Authenticate sets the bearer token.
+func (t Bearer) Authenticate(req *http.Request) error {
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+string(t))
+	return nil
+}
+
+// ClientCredentials authenticates with access tokens of the OAuth2 client
+// credentials grant, the OAuth2Application requirement of the spec. A token
+// is requested at TokenURL, e.g. http://localhost:1323/oauth/token, when
+// the last one is about to expire. Failed token requests return an
+// *oauth.Error.
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient sends the token requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Authenticate sets a current access token.
+func (c *ClientCredentials) Authenticate(req *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == "" || time.Now().After(c.expiry) {
+		if err := c.refresh(req); err != nil {
+			return err
+		}
+	}
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+c.token)
+	return nil
+}
+
+// refresh requests a token for the context of req. c.mu must be held.
+func (c *ClientCredentials) refresh(req *http.Request) error {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	tr, err := http.NewRequest(http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	tr = tr.WithContext(req.Context())
+	tr.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	tr.SetBasicAuth(c.ClientID, c.ClientSecret)
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(tr)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		e := &oauth.Error{}
+		if err := json.NewDecoder(res.Body).Decode(e); err != nil || e.Code == "" {
+			return &oauth.Error{Code: "server_error", Description: res.Status}
+		}
+		return e
+	}
+	var t struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&t); err != nil {
+		return err
+	}
+	c.token = t.AccessToken
+	// Renew a little early so that the token does not expire in flight.
+	c.expiry = time.Now().Add(time.Duration(t.ExpiresIn)*time.Second - 10*time.Second)
+	return nil
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/hexaforce/swagger-echo/model"
+)
+
+// ShowBottle returns the bottle id.
+func (c *Client) ShowBottle(ctx context.Context, id int) (model.Bottle, error) {
+	var bottle model.Bottle
+	r, err := jsonRequest(http.MethodGet, "/bottles/"+strconv.Itoa(id), nil, nil)
+	if err != nil {
+		return bottle, err
+	}
+	_, err = c.do(ctx, r, &bottle)
+	return bottle, err
+}
+
+// ListBottles returns the bottles and the page they are on.
+func (c *Client) ListBottles(ctx context.Context, opts model.ListOptions) ([]model.Bottle, model.Page, error) {
+	var bottles []model.Bottle
+	r, err := jsonRequest(http.MethodGet, "/bottles", listQuery(opts), nil)
+	if err != nil {
+		return nil, model.Page{}, err
+	}
+	h, err := c.do(ctx, r, &bottles)
+	if err != nil {
+		return nil, model.Page{}, err
+	}
+	return bottles, pageOf(h), nil
+}
+
+// AddBottle creates a bottle.
+func (c *Client) AddBottle(ctx context.Context, add model.AddBottle) (model.Bottle, error) {
+	var bottle model.Bottle
+	r, err := jsonRequest(http.MethodPost, "/bottles", nil, add)
+	if err != nil {
+		return bottle, err
+	}
+	_, err = c.do(ctx, r, &bottle)
+	return bottle, err
+}
+
+// ReplaceBottle replaces the bottle id.
+func (c *Client) ReplaceBottle(ctx context.Context, id int, replace model.AddBottle) (model.Bottle, error) {
+	var bottle model.Bottle
+	r, err := jsonRequest(http.MethodPut, "/bottles/"+strconv.Itoa(id), nil, replace)
+	if err != nil {
+		return bottle, err
+	}
+	_, err = c.do(ctx, r, &bottle)
+	return bottle, err
+}
+
+// UpdateBottle changes the fields of the bottle id that update sets.
+func (c *Client) UpdateBottle(ctx context.Context, id int, update model.UpdateBottle) (model.Bottle, error) {
+	var bottle model.Bottle
+	r, err := jsonRequest(http.MethodPatch, "/bottles/"+strconv.Itoa(id), nil, update)
+	if err != nil {
+		return bottle, err
+	}
+	_, err = c.do(ctx, r, &bottle)
+	return bottle, err
+}
+
+// DeleteBottle deletes the bottle id.
+func (c *Client) DeleteBottle(ctx context.Context, id int) error {
+	r, err := jsonRequest(http.MethodDelete, "/bottles/"+strconv.Itoa(id), nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, r, nil)
+	return err
+}
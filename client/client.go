@@ -0,0 +1,236 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/labstack/echo"
+)
+
+// HeaderRetryAfter is the number of seconds to wait before retrying a 429
+// or 503 response.
+const HeaderRetryAfter = "Retry-After"
+
+// Client calls the celler API.
+type Client struct {
+	// BaseURL is the URL of the API including the basePath, e.g.
+	// http://localhost:1323/api/v1.
+	BaseURL string
+	// HTTPClient sends the requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+	// Credentials authenticate every request; requests are sent without
+	// credentials when nil.
+	Credentials Authenticator
+	// Retry decides how failed requests are retried.
+	Retry Retry
+}
+
+// New returns a Client of the API at baseURL retrying with DefaultRetry.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), Retry: DefaultRetry}
+}
+
+// Retry retries idempotent requests (GET, HEAD, PUT and DELETE) that failed
+// to be sent or were answered 429, 502, 503 or 504, waiting Wait before the
+// first retry and twice as long before each further one, up to MaxWait. A
+// Retry-After header in seconds is honoured as it is, and the request is not
+// retried when it asks for longer than MaxWait; further retries go on with
+// the backoff.
+type Retry struct {
+	// Max is the number of retries, none when zero.
+	Max     int
+	Wait    time.Duration
+	MaxWait time.Duration
+}
+
+// DefaultRetry retries three times after 100ms, 200ms and 400ms.
+var DefaultRetry = Retry{Max: 3, Wait: 100 * time.Millisecond, MaxWait: 2 * time.Second}
+
+// StatusOf returns the status of the problem err is or wraps, 0 for other
+// errors such as failures to reach the server.
+func StatusOf(err error) int {
+	var p *httputil.Problem
+	if errors.As(err, &p) {
+		return p.Status
+	}
+	return 0
+}
+
+// request is a request of the API, replayed on retries.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	contentType string
+	body        []byte
+	// auth is false for requests that must not carry the credentials of
+	// the client, e.g. a refresh token exchange.
+	auth bool
+}
+
+// jsonRequest returns an authenticated request with v as the JSON body,
+// without body when v is nil.
+func jsonRequest(method, path string, query url.Values, v interface{}) (*request, error) {
+	r := &request{method: method, path: path, query: query, auth: true}
+	if v != nil {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		r.contentType, r.body = echo.MIMEApplicationJSON, b
+	}
+	return r, nil
+}
+
+// do sends r and decodes the JSON response into out unless it is nil.
+func (c *Client) do(ctx context.Context, r *request, out interface{}) (http.Header, error) {
+	res, err := c.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if out == nil {
+		_, err = io.Copy(ioutil.Discard, res.Body)
+		return res.Header, err
+	}
+	return res.Header, json.NewDecoder(res.Body).Decode(out)
+}
+
+// send sends r until it succeeds or may not be retried. Error responses are
+// returned as *httputil.Problem; the caller closes the body of the others.
+func (c *Client) send(ctx context.Context, r *request) (*http.Response, error) {
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	u := c.BaseURL + r.path
+	if len(r.query) > 0 {
+		u += "?" + r.query.Encode()
+	}
+	idempotent := r.method == http.MethodGet || r.method == http.MethodHead || r.method == http.MethodPut || r.method == http.MethodDelete
+	backoff := c.Retry.Wait
+	for attempt := 0; ; attempt++ {
+		if c.Retry.MaxWait > 0 && backoff > c.Retry.MaxWait {
+			backoff = c.Retry.MaxWait
+		}
+		wait := backoff
+		req, err := http.NewRequest(r.method, u, bytes.NewReader(r.body))
+		if err != nil {
+			return nil, err
+		}
+		req = req.WithContext(ctx)
+		if r.contentType != "" {
+			req.Header.Set(echo.HeaderContentType, r.contentType)
+		}
+		req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON+", "+httputil.MIMEProblemJSON+", */*")
+		if r.auth && c.Credentials != nil {
+			if err := c.Credentials.Authenticate(req); err != nil {
+				return nil, err
+			}
+		}
+		res, err := hc.Do(req)
+		retry := idempotent && attempt < c.Retry.Max
+		if err == nil {
+			if res.StatusCode < http.StatusBadRequest {
+				return res, nil
+			}
+			retry = retry && retryable(res.StatusCode)
+			if after := res.Header.Get(HeaderRetryAfter); retry && after != "" {
+				if s, err := strconv.Atoi(after); err == nil && s >= 0 {
+					wait = time.Duration(s) * time.Second
+					retry = c.Retry.MaxWait <= 0 || wait <= c.Retry.MaxWait
+				}
+			}
+			err = problemOf(res)
+			res.Body.Close()
+		}
+		if !retry || ctx.Err() != nil {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// problemOf decodes the problem of an error response. Responses that are
+// not problems, e.g. of a proxy, become a problem with the body as detail.
+func problemOf(res *http.Response) error {
+	b, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get(echo.HeaderContentType))
+	if mediaType == httputil.MIMEProblemJSON || mediaType == echo.MIMEApplicationJSON {
+		var p httputil.Problem
+		if err := json.Unmarshal(b, &p); err == nil && p.Status != 0 {
+			return &p
+		}
+	}
+	return httputil.NewProblem(res.StatusCode, strings.TrimSpace(string(b)))
+}
+
+// listQuery encodes the paging and ordering of opts.
+func listQuery(opts model.ListOptions) url.Values {
+	q := url.Values{}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		q.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if opts.Cursor != "" {
+		q.Set("cursor", opts.Cursor)
+	}
+	if len(opts.Sort) > 0 {
+		fields := make([]string, len(opts.Sort))
+		for i, k := range opts.Sort {
+			fields[i] = k.Field
+			if k.Desc {
+				fields[i] = "-" + k.Field
+			}
+		}
+		q.Set("sort", strings.Join(fields, ","))
+	}
+	return q
+}
+
+// pageOf reads the X-Total-Count header and the cursor of the next link.
+func pageOf(h http.Header) model.Page {
+	var page model.Page
+	page.Total, _ = strconv.Atoi(h.Get("X-Total-Count"))
+	for _, link := range strings.Split(h.Get("Link"), ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 || strings.TrimSpace(parts[1]) != `rel="next"` {
+			continue
+		}
+		u, err := url.Parse(strings.Trim(strings.TrimSpace(parts[0]), "<>"))
+		if err == nil {
+			page.Next = u.Query().Get("cursor")
+		}
+	}
+	return page
+}
@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/oauth"
+	"github.com/hexaforce/swagger-echo/server"
+	"github.com/labstack/echo"
+)
+
+const (
+	testAdminKey     = "test-admin-key"
+	testClientSecret = "test-client-secret"
+)
+
+// counter counts the requests of each path and answers the first
+// failures[path] of them 503 in place of the API, as a proxy would. The
+// failures send the successive Retry-After headers of retryAfter, the last
+// one repeating.
+type counter struct {
+	next       http.Handler
+	retryAfter []string
+
+	mu       sync.Mutex
+	requests map[string]int
+	failures map[string]int
+	failed   int
+}
+
+func (c *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	c.requests[r.URL.Path]++
+	fail := c.failures[r.URL.Path] > 0
+	retryAfter := ""
+	if fail {
+		c.failures[r.URL.Path]--
+		if n := len(c.retryAfter); n > 0 {
+			if c.failed < n {
+				retryAfter = c.retryAfter[c.failed]
+			} else {
+				retryAfter = c.retryAfter[n-1]
+			}
+		}
+		c.failed++
+	}
+	c.mu.Unlock()
+	if !fail {
+		c.next.ServeHTTP(w, r)
+		return
+	}
+	if retryAfter != "" {
+		w.Header().Set(HeaderRetryAfter, retryAfter)
+	}
+	http.Error(w, "upstream is unavailable", http.StatusServiceUnavailable)
+}
+
+// count returns the number of requests of path and answers the next fail of
+// them 503.
+func (c *counter) count(path string, fail int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures[path] = fail
+	c.failed = 0
+	return c.requests[path]
+}
+
+// newTestServer runs the API with the admin key testAdminKey and the OAuth2
+// client celler behind a counter.
+func newTestServer(t *testing.T) (*httptest.Server, *counter) {
+	t.Helper()
+	cfg := server.DefaultConfig()
+	cfg.BlobDir = t.TempDir()
+	cfg.AdminKey = testAdminKey
+	cfg.OAuthClientSecret = testClientSecret
+	e := echo.New()
+	srv, err := server.New(e, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &counter{next: e, requests: map[string]int{}, failures: map[string]int{}}
+	ts := httptest.NewServer(c)
+	t.Cleanup(func() {
+		ts.Close()
+		srv.Close()
+	})
+	return ts, c
+}
+
+func TestProblem(t *testing.T) {
+	ts, _ := newTestServer(t)
+	c := New(ts.URL + "/api/v1")
+	c.Credentials = APIKey(testAdminKey)
+
+	_, err := c.ShowAccount(context.Background(), "999")
+	var p *httputil.Problem
+	if !errors.As(err, &p) {
+		t.Fatalf("ShowAccount(999): %v, want a problem", err)
+	}
+	if p.Status != http.StatusNotFound || p.Title != "Not Found" || p.Instance != "/api/v1/accounts/999" || p.RequestID == "" {
+		t.Errorf("ShowAccount(999): %+v", p)
+	}
+	if StatusOf(err) != http.StatusNotFound {
+		t.Errorf("StatusOf = %d, want %d", StatusOf(err), http.StatusNotFound)
+	}
+
+	_, err = c.AddBottle(context.Background(), model.AddBottle{Name: "bottle", AccountID: -1})
+	if !errors.As(err, &p) || p.Status != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Pointer != "/account_id" {
+		t.Errorf("AddBottle of account -1: %v, want a 400 problem listing account_id", err)
+	}
+
+	c.Credentials = APIKey("wrong")
+	if _, _, err := c.ListAccounts(context.Background(), AccountQuery{}); StatusOf(err) != http.StatusUnauthorized {
+		t.Errorf("ListAccounts with a wrong key: %v, want status %d", err, http.StatusUnauthorized)
+	}
+
+	if got := StatusOf(errors.New("connection refused")); got != 0 {
+		t.Errorf("StatusOf of a transport error = %d, want 0", got)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ts, cnt := newTestServer(t)
+	c := New(ts.URL + "/api/v1")
+	c.Credentials = APIKey(testAdminKey)
+	const path = "/api/v1/accounts/1"
+
+	// Retry-After is honoured over the far longer backoff.
+	cnt.retryAfter = []string{"0"}
+	c.Retry = Retry{Max: 3, Wait: time.Hour, MaxWait: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	before := cnt.count(path, 2)
+	if a, err := c.ShowAccount(ctx, "1"); err != nil || a.ID != 1 {
+		t.Fatalf("ShowAccount(1) after two 503: %+v, %v", a, err)
+	}
+	if n := cnt.count(path, 0) - before; n != 3 {
+		t.Errorf("sent %d requests, want 3", n)
+	}
+
+	// A Retry-After longer than MaxWait is not waited for.
+	cnt.retryAfter = []string{"120"}
+	c.Retry = Retry{Max: 3, Wait: 10 * time.Millisecond, MaxWait: time.Second}
+	before = cnt.count(path, 2)
+	start := time.Now()
+	_, err := c.ShowAccount(context.Background(), "1")
+	if elapsed, n := time.Since(start), cnt.count(path, 0)-before; StatusOf(err) != http.StatusServiceUnavailable || n != 1 || elapsed > time.Second {
+		t.Errorf("ShowAccount(1) answered Retry-After: 120: %v after %d requests in %v, want the 503 at once", err, n, elapsed)
+	}
+
+	// After a Retry-After the backoff goes on: 0s, then 40ms for the second
+	// retry rather than twice the 0s the server asked for.
+	cnt.retryAfter = []string{"0", ""}
+	c.Retry = Retry{Max: 3, Wait: 20 * time.Millisecond, MaxWait: time.Second}
+	before = cnt.count(path, 2)
+	start = time.Now()
+	if _, err := c.ShowAccount(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed, n := time.Since(start), cnt.count(path, 0)-before; n != 3 || elapsed < 40*time.Millisecond {
+		t.Errorf("sent %d requests in %v, want 3 in at least 40ms", n, elapsed)
+	}
+
+	// Without Retry-After the waits double: 10ms, 20ms, 40ms.
+	cnt.retryAfter = nil
+	c.Retry = Retry{Max: 3, Wait: 10 * time.Millisecond, MaxWait: time.Second}
+	before = cnt.count(path, 10)
+	start = time.Now()
+	_, err = c.ShowAccount(context.Background(), "1")
+	elapsed := time.Since(start)
+	n := cnt.count(path, 0) - before
+	var p *httputil.Problem
+	if !errors.As(err, &p) || p.Status != http.StatusServiceUnavailable || p.Detail != "upstream is unavailable" {
+		t.Errorf("ShowAccount(1) after retries: %v, want the 503 of the proxy", err)
+	}
+	if n != 4 || elapsed < 70*time.Millisecond {
+		t.Errorf("sent %d requests in %v, want 4 in at least 70ms", n, elapsed)
+	}
+
+	// Requests that are not idempotent are sent once.
+	before = cnt.count("/api/v1/accounts", 1)
+	if _, err := c.AddAccount(context.Background(), model.AddAccount{Name: "retried"}); StatusOf(err) != http.StatusServiceUnavailable {
+		t.Errorf("AddAccount: %v, want status %d", err, http.StatusServiceUnavailable)
+	}
+	if n := cnt.count("/api/v1/accounts", 0) - before; n != 1 {
+		t.Errorf("AddAccount sent %d requests, want 1", n)
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	ts, cnt := newTestServer(t)
+	cc := &ClientCredentials{
+		TokenURL:     ts.URL + "/oauth/token",
+		ClientID:     "celler",
+		ClientSecret: testClientSecret,
+		Scopes:       []string{"read"},
+	}
+	c := New(ts.URL + "/api/v1")
+	c.Credentials = cc
+	list := func() {
+		t.Helper()
+		if _, _, err := c.ListAccounts(context.Background(), AccountQuery{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list()
+	list()
+	if n := cnt.count("/oauth/token", 0); n != 1 {
+		t.Errorf("requested %d tokens for two calls, want 1", n)
+	}
+	cc.mu.Lock()
+	first := cc.token
+	cc.expiry = time.Now().Add(-time.Second)
+	cc.mu.Unlock()
+	list()
+	if n := cnt.count("/oauth/token", 0); n != 2 {
+		t.Errorf("requested %d tokens after expiry, want 2", n)
+	}
+	if cc.token == first {
+		t.Error("the expired token is still used")
+	}
+
+	c.Credentials = &ClientCredentials{TokenURL: cc.TokenURL, ClientID: "celler", ClientSecret: "wrong"}
+	_, _, err := c.ListAccounts(context.Background(), AccountQuery{})
+	var oe *oauth.Error
+	if !errors.As(err, &oe) || oe.Code != "invalid_client" {
+		t.Errorf("wrong client secret: %v, want invalid_client", err)
+	}
+}
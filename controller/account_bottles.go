@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/labstack/echo"
+)
+
+// ListAccountBottles godoc
+// @Summary List account bottles
+// @Description get bottles owned by an account
+// @Tags accounts,bottles
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Account ID"
+// @Param limit query int false "maximum number of bottles to return" minimum(0)
+// @Param offset query int false "number of bottles to skip" minimum(0)
+// @Param cursor query string false "cursor of the next page, taken from a previous Link header"
+// @Param sort query string false "comma separated sort fields (id, name), prefix - for descending"
+// @Success 200 {array} model.Bottle
+// @Header 200 {string} Link "links to the first, prev, next and last pages"
+// @Header 200 {integer} X-Total-Count "number of bottles owned by the account"
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /accounts/{id}/bottles [get]
+func (c *Controller) ListAccountBottles(ctx echo.Context) error {
+	aid, err := c.accountParam(ctx)
+	if err != nil {
+		return err
+	}
+	return c.listBottles(ctx, model.Compare{Field: "account_id", Op: model.OpEq, Value: int64(aid)})
+}
+
+// AddAccountBottle godoc
+// @Summary Add a account bottle
+// @Description add by json bottle owned by the account
+// @Tags accounts,bottles
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Account ID"
+// @Param bottle body model.AddAccountBottle true "Add bottle"
+// @Success 200 {object} model.Bottle
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /accounts/{id}/bottles [post]
+func (c *Controller) AddAccountBottle(ctx echo.Context) error {
+	aid, err := c.accountParam(ctx)
+	if err != nil {
+		return err
+	}
+	var addBottle model.AddAccountBottle
+	if err := ctx.Bind(&addBottle); err != nil {
+		return err
+	}
+	return c.addBottle(ctx, model.AddBottle{Name: addBottle.Name, AccountID: aid})
+}
+
+// ShowAccountBottle godoc
+// @Summary Show a account bottle
+// @Description get bottle by ID, only if the account owns it
+// @Tags accounts,bottles
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Account ID"
+// @Param bottle_id path int true "Bottle ID"
+// @Success 200 {object} model.Bottle
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /accounts/{id}/bottles/{bottle_id} [get]
+func (c *Controller) ShowAccountBottle(ctx echo.Context) error {
+	aid, err := c.accountParam(ctx)
+	if err != nil {
+		return err
+	}
+	bid, err := intParam(ctx, "bottle_id")
+	if err != nil {
+		return err
+	}
+	bottle, err := c.bottles.BottleOne(bid)
+	if err != nil {
+		return err
+	}
+	if bottle.AccountID() != aid {
+		return model.ErrNoRow
+	}
+	return ctx.JSON(http.StatusOK, bottle)
+}
+
+// accountParam reads the id path parameter and checks that the account exists.
+func (c *Controller) accountParam(ctx echo.Context) (int, error) {
+	aid, err := intParam(ctx, "id")
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.accounts.AccountOne(aid); err != nil {
+		return 0, err
+	}
+	return aid, nil
+}
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	goimage "image"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/hexaforce/swagger-echo/blob"
+	"github.com/hexaforce/swagger-echo/imageutil"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/labstack/echo"
+	uuid "github.com/satori/go.uuid"
+)
+
+// UploadAccountImage godoc
+// @Summary Upload account image
+// @Description Upload a PNG, JPEG or GIF image; the content type is sniffed from the content and thumbnails are generated
+// @Tags accounts
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param  id path int true "Account ID"
+// @Param file formData file true "account image"
+// @Success 200 {object} model.Image
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 413 {object} httputil.Problem
+// @Failure 415 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /accounts/{id}/images [post]
+func (c *Controller) UploadAccountImage(ctx echo.Context) error {
+	aid, err := c.accountParam(ctx)
+	if err != nil {
+		return err
+	}
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if file.Size > c.MaxImageSize {
+		return imageutil.ErrTooLarge
+	}
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	data, contentType, img, err := imageutil.Decode(src, imageutil.Limits{MaxSize: c.MaxImageSize, MaxPixels: c.MaxImagePixels})
+	if err != nil {
+		return err
+	}
+	image := model.Image{
+		ID:          uuid.Must(uuid.NewV4()),
+		AccountID:   aid,
+		Filename:    filepath.Base(file.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		CreatedAt:   time.Now().UTC(),
+	}
+	sum := sha256.Sum256(data)
+	image.SHA256 = hex.EncodeToString(sum[:])
+	if err := c.putImage(image, data, img); err != nil {
+		c.deleteImageBlobs(ctx, []model.Image{image})
+		return err
+	}
+	if err := c.images.InsertImage(image); err != nil {
+		c.deleteImageBlobs(ctx, []model.Image{image})
+		return err
+	}
+	return ctx.JSON(http.StatusOK, image)
+}
+
+// putImage stores the content of an image and its thumbnails.
+func (c *Controller) putImage(image model.Image, data []byte, img goimage.Image) error {
+	if _, err := c.blobs.Put(image.BlobKey(), bytes.NewReader(data)); err != nil {
+		return err
+	}
+	for _, size := range c.ThumbnailSizes {
+		var buf bytes.Buffer
+		if err := imageutil.Encode(&buf, imageutil.Thumbnail(img, size.Max), imageutil.ThumbnailType(image.ContentType)); err != nil {
+			return err
+		}
+		if _, err := c.blobs.Put(image.ThumbnailKey(size.Name), &buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListAccountImages godoc
+// @Summary List account images
+// @Description get metadata of the images uploaded for an account
+// @Tags accounts
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Account ID"
+// @Success 200 {array} model.Image
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /accounts/{id}/images [get]
+func (c *Controller) ListAccountImages(ctx echo.Context) error {
+	aid, err := c.accountParam(ctx)
+	if err != nil {
+		return err
+	}
+	images, err := c.images.ImagesAll(aid)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, images)
+}
+
+// ShowAccountImage godoc
+// @Summary Show a account image
+// @Description stream the image content; supports Range requests
+// @Tags accounts
+// @Produce  application/octet-stream,plain
+// @Param id path int true "Account ID"
+// @Param image_id path string true "Image ID" Format(uuid)
+// @Param size query string false "thumbnail size, e.g. thumb or medium; the original when empty"
+// @Param Range header string false "byte range to return, e.g. bytes=0-1023"
+// @Success 200 {file} file "image content"
+// @Success 206 {file} file "partial image content"
+// @Success 304
+// @Header 200 {string} ETag "SHA-256 of the image"
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 416 {string} string "range not satisfiable"
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /accounts/{id}/images/{image_id} [get]
+func (c *Controller) ShowAccountImage(ctx echo.Context) error {
+	image, err := c.imageParam(ctx)
+	if err != nil {
+		return err
+	}
+	key, contentType, etag := image.BlobKey(), image.ContentType, image.SHA256
+	if size := ctx.QueryParam("size"); size != "" {
+		if !c.hasThumbnailSize(size) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("size=%s is not a thumbnail size", size))
+		}
+		key, contentType, etag = image.ThumbnailKey(size), imageutil.ThumbnailType(image.ContentType), image.SHA256+"-"+size
+	}
+	f, err := c.blobs.Open(key)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := ctx.Response().Header()
+	h.Set(echo.HeaderContentType, contentType)
+	h.Set("ETag", `"`+etag+`"`)
+	http.ServeContent(ctx.Response(), ctx.Request(), image.Filename, image.CreatedAt, f)
+	return nil
+}
+
+// DeleteAccountImage godoc
+// @Summary Delete a account image
+// @Description Delete by account ID and image ID
+// @Tags accounts
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Account ID"
+// @Param image_id path string true "Image ID" Format(uuid)
+// @Success 204
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /accounts/{id}/images/{image_id} [delete]
+func (c *Controller) DeleteAccountImage(ctx echo.Context) error {
+	image, err := c.imageParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.images.DeleteImage(image.AccountID, image.ID); err != nil {
+		return err
+	}
+	c.deleteImageBlobs(ctx, []model.Image{image})
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// imageParam reads the id and image_id path parameters and loads the image metadata.
+func (c *Controller) imageParam(ctx echo.Context) (model.Image, error) {
+	aid, err := c.accountParam(ctx)
+	if err != nil {
+		return model.Image{}, err
+	}
+	id, err := uuidParam(ctx, "image_id")
+	if err != nil {
+		return model.Image{}, err
+	}
+	image, err := c.images.ImageOne(aid, id)
+	if err != nil {
+		return model.Image{}, err
+	}
+	return image, nil
+}
+
+// hasThumbnailSize reports whether name is one of the configured thumbnail sizes.
+func (c *Controller) hasThumbnailSize(name string) bool {
+	for _, size := range c.ThumbnailSizes {
+		if size.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteImageBlobs removes the stored content and thumbnails of images.
+// The metadata is already gone, so failures are logged rather than
+// returned; blobs that were never stored are skipped.
+func (c *Controller) deleteImageBlobs(ctx echo.Context, images []model.Image) {
+	for _, image := range images {
+		keys := []string{image.BlobKey()}
+		for _, size := range c.ThumbnailSizes {
+			keys = append(keys, image.ThumbnailKey(size.Name))
+		}
+		for _, key := range keys {
+			if err := c.blobs.Delete(key); err != nil && err != blob.ErrNotExist {
+				ctx.Logger().Errorf("deleting blob %s of image %s: %v", key, image.ID, err)
+			}
+		}
+	}
+}
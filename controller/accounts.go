@@ -5,32 +5,45 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/gin-gonic/gin"
 	"github.com/hexaforce/swagger-echo/model"
 	"github.com/labstack/echo"
+	uuid "github.com/satori/go.uuid"
 )
 
 // ShowAccount godoc
 // @Summary Show a account
-// @Description get string by ID
+// @Description get account by ID or UUID
 // @Tags accounts
 // @Accept  json
 // @Produce  json
-// @Param id path int true "Account ID"
+// @Param id path string true "Account ID or UUID"
 // @Success 200 {object} model.Account
-// @Failure 400 {object} httputil.HTTPError
-// @Failure 404 {object} httputil.HTTPError
-// @Failure 500 {object} httputil.HTTPError
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
 // @Router /accounts/{id} [get]
 func (c *Controller) ShowAccount(ctx echo.Context) error {
 	id := ctx.Param("id")
-	aid, err := strconv.Atoi(id)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
-	}
-	account, err := model.AccountOne(aid)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, err.Error)
+	var account model.Account
+	if aid, err := strconv.Atoi(id); err == nil {
+		account, err = c.accounts.AccountOne(aid)
+		if err != nil {
+			return err
+		}
+	} else {
+		u, err := uuid.FromString(id)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("id=%s is neither an integer nor a UUID", id))
+		}
+		account, err = c.accounts.AccountByUUID(u)
+		if err != nil {
+			return err
+		}
 	}
 	return ctx.JSON(http.StatusOK, account)
 }
@@ -41,18 +54,46 @@ func (c *Controller) ShowAccount(ctx echo.Context) error {
 // @Tags accounts
 // @Accept  json
 // @Produce  json
-// @Param q query string false "name search by q" Format(email)
+// @Param q query string false "name search by q"
+// @Param filter query string false "filter over id, name, uuid and owner_id with eq, ne, gt, ge, lt, le, sw, ew, co (ieq, isw, iew, ico ignore case) joined by and, or, not"
+// @Param limit query int false "maximum number of accounts to return" minimum(0)
+// @Param offset query int false "number of accounts to skip" minimum(0)
+// @Param cursor query string false "cursor of the next page, taken from a previous Link header"
+// @Param sort query string false "comma separated sort fields (id, name), prefix - for descending"
 // @Success 200 {array} model.Account
-// @Failure 400 {object} httputil.HTTPError
-// @Failure 404 {object} httputil.HTTPError
-// @Failure 500 {object} httputil.HTTPError
+// @Header 200 {string} Link "links to the first, prev, next and last pages"
+// @Header 200 {integer} X-Total-Count "number of matching accounts"
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
 // @Router /accounts [get]
 func (c *Controller) ListAccounts(ctx echo.Context) error {
-	q := ctx.QueryParam("q")
-	accounts, err := model.AccountsAll(q)
+	filter, err := model.ParseFilter(ctx.QueryParam("filter"), model.AccountFilterFields)
+	if err != nil {
+		return err
+	}
+	if q := ctx.QueryParam("q"); q != "" {
+		name := model.Compare{Field: "name", Op: model.OpEq, Value: q}
+		if filter == nil {
+			filter = name
+		} else {
+			filter = model.And{Left: name, Right: filter}
+		}
+	}
+	opts, err := listOptions(ctx, model.AccountSortFields...)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, err.Error)
+		return err
 	}
+	accounts, page, err := c.accounts.AccountsAll(filter, opts)
+	if err != nil {
+		return err
+	}
+	setPageHeaders(ctx, opts, page, len(accounts))
 	return ctx.JSON(http.StatusOK, accounts)
 }
 
@@ -64,26 +105,32 @@ func (c *Controller) ListAccounts(ctx echo.Context) error {
 // @Produce  json
 // @Param account body model.AddAccount true "Add account"
 // @Success 200 {object} model.Account
-// @Failure 400 {object} httputil.HTTPError
-// @Failure 404 {object} httputil.HTTPError
-// @Failure 500 {object} httputil.HTTPError
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
 // @Router /accounts [post]
 func (c *Controller) AddAccount(ctx echo.Context) error {
 	var addAccount model.AddAccount
 	if err := ctx.Bind(&addAccount); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
-	}
-	if err := addAccount.Validation(); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return err
 	}
 	account := model.Account{
-		Name: addAccount.Name,
+		Name:    addAccount.Name,
+		OwnerID: principalID(ctx),
+	}
+	lastID, err := c.accounts.Insert(account)
+	if err != nil {
+		return err
 	}
-	lastID, err := account.Insert()
+	account, err = c.accounts.AccountOne(lastID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return err
 	}
-	account.ID = lastID
 	return ctx.JSON(http.StatusOK, account)
 }
 
@@ -96,27 +143,34 @@ func (c *Controller) AddAccount(ctx echo.Context) error {
 // @Param  id path int true "Account ID"
 // @Param  account body model.UpdateAccount true "Update account"
 // @Success 200 {object} model.Account
-// @Failure 400 {object} httputil.HTTPError
-// @Failure 404 {object} httputil.HTTPError
-// @Failure 500 {object} httputil.HTTPError
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
 // @Router /accounts/{id} [patch]
 func (c *Controller) UpdateAccount(ctx echo.Context) error {
-	id := ctx.Param("id")
-	aid, err := strconv.Atoi(id)
+	aid, err := intParam(ctx, "id")
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return err
 	}
 	var updateAccount model.UpdateAccount
 	if err := ctx.Bind(&updateAccount); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return err
 	}
 	account := model.Account{
 		ID:   aid,
 		Name: updateAccount.Name,
 	}
-	err = account.Update()
+	if err := c.accounts.Update(account); err != nil {
+		return err
+	}
+	account, err = c.accounts.AccountOne(aid)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, err.Error)
+		return err
 	}
 	return ctx.JSON(http.StatusOK, account)
 }
@@ -128,45 +182,29 @@ func (c *Controller) UpdateAccount(ctx echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param  id path int true "Account ID" Format(int64)
-// @Success 204 {object} model.Account
-// @Failure 400 {object} httputil.HTTPError
-// @Failure 404 {object} httputil.HTTPError
-// @Failure 500 {object} httputil.HTTPError
+// @Success 204
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 409 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
 // @Router /accounts/{id} [delete]
 func (c *Controller) DeleteAccount(ctx echo.Context) error {
-	id := ctx.Param("id")
-	aid, err := strconv.Atoi(id)
+	aid, err := intParam(ctx, "id")
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return err
 	}
-	err = model.Delete(aid)
+	images, err := c.images.ImagesAll(aid)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, err.Error)
+		return err
 	}
-	return ctx.JSON(http.StatusNoContent, gin.H{})
-}
-
-// UploadAccountImage godoc
-// @Summary Upload account image
-// @Description Upload file
-// @Tags accounts
-// @Accept  multipart/form-data
-// @Produce  json
-// @Param  id path int true "Account ID"
-// @Param file formData file true "account image"
-// @Success 200 {object} controller.Message
-// @Failure 400 {object} httputil.HTTPError
-// @Failure 404 {object} httputil.HTTPError
-// @Failure 500 {object} httputil.HTTPError
-// @Router /accounts/{id}/images [post]
-func (c *Controller) UploadAccountImage(ctx echo.Context) error {
-	id, err := strconv.Atoi(ctx.Param("id"))
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
-	}
-	file, err := ctx.FormFile("file")
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+	if err := c.accounts.Delete(aid); err != nil {
+		return err
 	}
-	return ctx.JSON(http.StatusOK, Message{Message: fmt.Sprintf("upload compleate userID=%d finename=%s", id, file.Filename)})
+	c.deleteImageBlobs(ctx, images)
+	return ctx.NoContent(http.StatusNoContent)
 }
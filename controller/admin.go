@@ -2,37 +2,141 @@ package controller
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 
+	"github.com/hexaforce/swagger-echo/auth"
 	"github.com/hexaforce/swagger-echo/model"
 	"github.com/labstack/echo"
 )
 
 // Auth godoc
 // @Summary Auth admin
-// @Description get admin info
+// @Description issue a JWT access and refresh token for the admin authenticated by API key or Basic credentials
 // @Tags accounts,admin
 // @Accept  json
 // @Produce  json
-// @Success 200 {object} model.Admin
-// @Failure 400 {object} httputil.HTTPError
-// @Failure 401 {object} httputil.HTTPError
-// @Failure 404 {object} httputil.HTTPError
-// @Failure 500 {object} httputil.HTTPError
+// @Success 200 {object} model.AdminToken
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
 // @Security ApiKeyAuth
+// @Security BasicAuth
 // @Router /admin/auth [post]
 func (c *Controller) Auth(ctx echo.Context) error {
-	authHeader := ctx.Request().Header.Get("Authorization")
-	if len(authHeader) == 0 {
-		return echo.NewHTTPError(http.StatusBadRequest, errors.New("please set Header Authorization").Error)
+	admin, ok := auth.Admin(ctx)
+	if !ok {
+		return auth.ErrNoCredentials
 	}
-	if authHeader != "admin" {
-		return echo.NewHTTPError(http.StatusUnauthorized, fmt.Errorf("this user isn't authorized to operation key=%s expected=admin", authHeader).Error)
+	t, err := c.Tokens.Issue(admin)
+	if err != nil {
+		return err
 	}
-	admin := model.Admin{
-		ID:   1,
-		Name: "admin",
+	return ctx.JSON(http.StatusOK, t)
+}
+
+// RefreshAuth godoc
+// @Summary Refresh admin token
+// @Description exchange a refresh token for a new token pair; the refresh token can be used once
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param token body model.RefreshToken true "Refresh token"
+// @Success 200 {object} model.AdminToken
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Router /admin/refresh [post]
+func (c *Controller) RefreshAuth(ctx echo.Context) error {
+	var refresh model.RefreshToken
+	if err := ctx.Bind(&refresh); err != nil {
+		return err
+	}
+	t, err := c.Tokens.Refresh(refresh.RefreshToken)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, t)
+}
+
+// RevokeAuth godoc
+// @Summary Revoke admin token
+// @Description revoke an access or refresh token until it expires
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param token body model.RevokeToken true "Token to revoke"
+// @Success 204 {string} string ""
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Router /admin/revoke [post]
+func (c *Controller) RevokeAuth(ctx echo.Context) error {
+	var revoke model.RevokeToken
+	if err := ctx.Bind(&revoke); err != nil {
+		return err
+	}
+	if err := c.Tokens.Revoke(revoke.Token); err != nil {
+		return err
 	}
-	return ctx.JSON(http.StatusOK, admin)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// ShowDrift godoc
+// @Summary Show route drift
+// @Description compare the registered routes with the operations of the enforced spec: undocumented routes, operations without a route and path parameters that differ
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} spec.Drift
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Router /admin/drift [get]
+func (c *Controller) ShowDrift(ctx echo.Context) error {
+	if _, err := c.requireAdmin(ctx, "showing route drift"); err != nil {
+		return err
+	}
+	if c.Spec == nil || c.Routes == nil {
+		return errors.New("no spec or routes to compare")
+	}
+	return ctx.JSON(http.StatusOK, c.Spec.Drift(c.Routes()))
+}
+
+// requireAdmin returns the authenticated admin if it may administer, what
+// describes the refused action. Scoped credentials, such as API keys and
+// the tokens issued for them, need the admin scope whatever roles they
+// carry; the admin role, see rolesOf, counts only for credentials without
+// scopes.
+func (c *Controller) requireAdmin(ctx echo.Context, what string) (model.Admin, error) {
+	admin, ok := auth.Admin(ctx)
+	if !ok {
+		return model.Admin{}, auth.ErrNoCredentials
+	}
+	if scoped(admin) {
+		if !hasString(admin.Scopes, "admin") {
+			return model.Admin{}, echo.NewHTTPError(http.StatusForbidden, what+" needs the admin scope")
+		}
+	} else if !hasString(c.rolesOf(admin), "admin") {
+		return model.Admin{}, echo.NewHTTPError(http.StatusForbidden, what+" needs the admin role")
+	}
+	return admin, nil
+}
+
+// rolesOf returns the roles admin holds under the Policy, the same roles
+// the routes are authorized with, or the roles of its credentials without a
+// Policy.
+func (c *Controller) rolesOf(admin model.Admin) []string {
+	if c.Policy == nil {
+		return admin.Roles
+	}
+	return c.Policy.RolesOf(admin)
+}
+
+// scoped reports whether admin authenticated with credentials limited to
+// scopes. Stored API keys always are.
+func scoped(admin model.Admin) bool {
+	return admin.KeyID != "" || len(admin.Scopes) > 0
 }
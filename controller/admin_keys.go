@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hexaforce/swagger-echo/auth"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/labstack/echo"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description get API keys; secrets are never returned
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.APIKey
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Router /admin/keys [get]
+func (c *Controller) ListAPIKeys(ctx echo.Context) error {
+	if err := c.keyManager(ctx, nil); err != nil {
+		return err
+	}
+	keys, err := c.keys.APIKeysAll()
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, keys)
+}
+
+// AddAPIKey godoc
+// @Summary Add a API key
+// @Description create an API key; the key is shown only in this response
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param key body model.AddAPIKey true "Add API key"
+// @Success 200 {object} model.NewAPIKey
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Router /admin/keys [post]
+func (c *Controller) AddAPIKey(ctx echo.Context) error {
+	var addKey model.AddAPIKey
+	if err := ctx.Bind(&addKey); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if err := addKey.Validation(now); err != nil {
+		return err
+	}
+	if err := c.keyManager(ctx, addKey.Scopes); err != nil {
+		return err
+	}
+	admin, _ := auth.Admin(ctx)
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+	k := model.APIKey{
+		ID:        uuid.Must(uuid.NewV4()),
+		Name:      addKey.Name,
+		Prefix:    prefix,
+		Hash:      hash,
+		AdminID:   admin.ID,
+		Scopes:    addKey.Scopes,
+		Roles:     c.assignedRoles(admin),
+		ExpiresAt: addKey.ExpiresAt,
+		CreatedAt: now,
+	}
+	if err := c.keys.InsertAPIKey(k); err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, model.NewAPIKey{APIKey: k, Key: key})
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate a API key
+// @Description replace the secret of an API key, keeping its name, scopes and expiry; the old key stops working and the new key is shown only in this response
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "API key ID" Format(uuid)
+// @Success 200 {object} model.NewAPIKey
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Router /admin/keys/{id}/rotate [post]
+func (c *Controller) RotateAPIKey(ctx echo.Context) error {
+	k, err := c.apiKeyParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.keyManager(ctx, k.Scopes); err != nil {
+		return err
+	}
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+	k.Prefix, k.Hash, k.LastUsedAt = prefix, hash, nil
+	if err := c.keys.UpdateAPIKey(k); err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, model.NewAPIKey{APIKey: k, Key: key})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke a API key
+// @Description delete an API key so that it stops working
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "API key ID" Format(uuid)
+// @Success 204 {string} string ""
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Router /admin/keys/{id} [delete]
+func (c *Controller) RevokeAPIKey(ctx echo.Context) error {
+	k, err := c.apiKeyParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.keyManager(ctx, k.Scopes); err != nil {
+		return err
+	}
+	if err := c.keys.DeleteAPIKey(k.ID); err != nil {
+		return err
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// apiKeyParam reads the id path parameter and loads the API key.
+func (c *Controller) apiKeyParam(ctx echo.Context) (model.APIKey, error) {
+	id, err := uuidParam(ctx, "id")
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	k, err := c.keys.APIKeyOne(id)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	return k, nil
+}
+
+// assignedRoles are the roles admin holds apart from the default roles,
+// which the keys it creates act with.
+func (c *Controller) assignedRoles(admin model.Admin) []string {
+	if c.Policy == nil {
+		return admin.Roles
+	}
+	return c.Policy.AssignedRoles(admin)
+}
+
+// keyManager checks that the authenticated admin may manage API keys with
+// the given scopes, see requireAdmin. Admins with the admin role and
+// credentials without scopes may grant any scope; scoped credentials such
+// as API keys can only grant the scopes they hold themselves.
+func (c *Controller) keyManager(ctx echo.Context, scopes []string) error {
+	admin, err := c.requireAdmin(ctx, "managing API keys")
+	if err != nil || !scoped(admin) {
+		return err
+	}
+	for _, s := range scopes {
+		if !hasString(admin.Scopes, s) {
+			return echo.NewHTTPError(http.StatusForbidden, "scope "+s+" is not held by the caller")
+		}
+	}
+	return nil
+}
+
+func hasString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hexaforce/swagger-echo/auth"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/rbac"
+	"github.com/labstack/echo"
+)
+
+func TestRequireAdminUsesPolicy(t *testing.T) {
+	store := model.NewMemoryStore()
+	c := NewController(store, store, store, nil, store)
+	c.Policy = rbac.DefaultPolicy()
+	c.Policy.Principals = map[string][]string{"ops": {"admin"}}
+	e := echo.New()
+
+	status := func(admin model.Admin) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/admin/keys", nil), rec)
+		auth.SetAdmin(ctx, admin)
+		if err := c.ListAPIKeys(ctx); err != nil {
+			e.HTTPErrorHandler(err, ctx)
+		}
+		return rec.Code
+	}
+
+	for _, tc := range []struct {
+		name       string
+		admin      model.Admin
+		wantStatus int
+	}{
+		{"admin by the policy", model.Admin{ID: 2, Name: "ops"}, http.StatusOK},
+		{"admin by its credentials", model.Admin{ID: 1, Name: "admin", Roles: []string{"admin"}}, http.StatusOK},
+		{"reader", model.Admin{ID: 3, Name: "guest"}, http.StatusForbidden},
+		{"scoped admin by the policy", model.Admin{ID: 2, Name: "ops", Scopes: []string{"read"}}, http.StatusForbidden},
+	} {
+		if got := status(tc.admin); got != tc.wantStatus {
+			t.Errorf("%s: status %d, want %d", tc.name, got, tc.wantStatus)
+		}
+	}
+}
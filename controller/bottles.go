@@ -2,9 +2,9 @@ package controller
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/rbac"
 	"github.com/labstack/echo"
 )
 
@@ -17,19 +17,23 @@ import (
 // @Produce  json
 // @Param  id path int true "Bottle ID"
 // @Success 200 {object} model.Bottle
-// @Failure 400 {object} httputil.HTTPError
-// @Failure 404 {object} httputil.HTTPError
-// @Failure 500 {object} httputil.HTTPError
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
 // @Router /bottles/{id} [get]
 func (c *Controller) ShowBottle(ctx echo.Context) error {
-	id := ctx.Param("id")
-	bid, err := strconv.Atoi(id)
+	bid, err := intParam(ctx, "id")
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return err
 	}
-	bottle, err := model.BottleOne(bid)
+	bottle, err := c.bottles.BottleOne(bid)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, err.Error)
+		return err
 	}
 	return ctx.JSON(http.StatusOK, bottle)
 }
@@ -40,15 +44,207 @@ func (c *Controller) ShowBottle(ctx echo.Context) error {
 // @Tags bottles
 // @Accept  json
 // @Produce  json
+// @Param limit query int false "maximum number of bottles to return" minimum(0)
+// @Param offset query int false "number of bottles to skip" minimum(0)
+// @Param cursor query string false "cursor of the next page, taken from a previous Link header"
+// @Param sort query string false "comma separated sort fields (id, name), prefix - for descending"
 // @Success 200 {array} model.Bottle
-// @Failure 400 {object} httputil.HTTPError
-// @Failure 404 {object} httputil.HTTPError
-// @Failure 500 {object} httputil.HTTPError
+// @Header 200 {string} Link "links to the first, prev, next and last pages"
+// @Header 200 {integer} X-Total-Count "number of bottles"
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
 // @Router /bottles [get]
 func (c *Controller) ListBottles(ctx echo.Context) error {
-	bottles, err := model.BottlesAll()
+	return c.listBottles(ctx, nil)
+}
+
+// AddBottle godoc
+// @Summary Add a bottle
+// @Description add by json bottle
+// @Tags bottles
+// @Accept  json
+// @Produce  json
+// @Param bottle body model.AddBottle true "Add bottle"
+// @Success 200 {object} model.Bottle
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /bottles [post]
+func (c *Controller) AddBottle(ctx echo.Context) error {
+	var addBottle model.AddBottle
+	if err := ctx.Bind(&addBottle); err != nil {
+		return err
+	}
+	return c.addBottle(ctx, addBottle)
+}
+
+func (c *Controller) addBottle(ctx echo.Context, addBottle model.AddBottle) error {
+	accountID, err := c.ownerOf(addBottle.AccountID, addBottle.AccountUUID)
+	if err != nil {
+		return err
+	}
+	bottle := model.Bottle{
+		Name:    addBottle.Name,
+		Account: &model.Account{ID: accountID},
+	}
+	lastID, err := c.bottles.InsertBottle(bottle)
+	if err != nil {
+		return err
+	}
+	return c.showBottle(ctx, lastID)
+}
+
+// ReplaceBottle godoc
+// @Summary Replace a bottle
+// @Description Replace by json bottle
+// @Tags bottles
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Bottle ID"
+// @Param  bottle body model.AddBottle true "Replace bottle"
+// @Success 200 {object} model.Bottle
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /bottles/{id} [put]
+func (c *Controller) ReplaceBottle(ctx echo.Context) error {
+	bid, err := intParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+	var addBottle model.AddBottle
+	if err := ctx.Bind(&addBottle); err != nil {
+		return err
+	}
+	accountID, err := c.ownerOf(addBottle.AccountID, addBottle.AccountUUID)
+	if err != nil {
+		return err
+	}
+	if err := c.authorizeAccount(ctx, rbac.BottlesUpdate, accountID); err != nil {
+		return err
+	}
+	bottle := model.Bottle{
+		ID:      bid,
+		Name:    addBottle.Name,
+		Account: &model.Account{ID: accountID},
+	}
+	if err := c.bottles.UpdateBottle(bottle); err != nil {
+		return err
+	}
+	return c.showBottle(ctx, bid)
+}
+
+// UpdateBottle godoc
+// @Summary Update a bottle
+// @Description Update by json bottle
+// @Tags bottles
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Bottle ID"
+// @Param  bottle body model.UpdateBottle true "Update bottle"
+// @Success 200 {object} model.Bottle
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /bottles/{id} [patch]
+func (c *Controller) UpdateBottle(ctx echo.Context) error {
+	bid, err := intParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+	var updateBottle model.UpdateBottle
+	if err := ctx.Bind(&updateBottle); err != nil {
+		return err
+	}
+	bottle, err := c.bottles.BottleOne(bid)
+	if err != nil {
+		return err
+	}
+	if updateBottle.Name != "" {
+		bottle.Name = updateBottle.Name
+	}
+	accountID, err := c.ownerOf(updateBottle.AccountID, updateBottle.AccountUUID)
+	if err != nil {
+		return err
+	}
+	if accountID != 0 && accountID != bottle.AccountID() {
+		if err := c.authorizeAccount(ctx, rbac.BottlesUpdate, accountID); err != nil {
+			return err
+		}
+		bottle.Account = &model.Account{ID: accountID}
+	}
+	if err := c.bottles.UpdateBottle(*bottle); err != nil {
+		return err
+	}
+	return c.showBottle(ctx, bid)
+}
+
+// DeleteBottle godoc
+// @Summary Delete a bottle
+// @Description Delete by bottle ID
+// @Tags bottles
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Bottle ID"
+// @Success 204
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security OAuth2Password
+// @Router /bottles/{id} [delete]
+func (c *Controller) DeleteBottle(ctx echo.Context) error {
+	bid, err := intParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+	if err := c.bottles.DeleteBottle(bid); err != nil {
+		return err
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *Controller) listBottles(ctx echo.Context, filter model.Filter) error {
+	opts, err := listOptions(ctx, model.BottleSortFields...)
+	if err != nil {
+		return err
+	}
+	bottles, page, err := c.bottles.BottlesAll(filter, opts)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, err.Error)
+		return err
 	}
+	setPageHeaders(ctx, opts, page, len(bottles))
 	return ctx.JSON(http.StatusOK, bottles)
 }
+
+func (c *Controller) showBottle(ctx echo.Context, id int) error {
+	bottle, err := c.bottles.BottleOne(id)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, bottle)
+}
@@ -1,12 +1,51 @@
 package controller
 
+import (
+	"github.com/hexaforce/swagger-echo/blob"
+	"github.com/hexaforce/swagger-echo/imageutil"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/rbac"
+	"github.com/hexaforce/swagger-echo/spec"
+	"github.com/hexaforce/swagger-echo/token"
+	"github.com/labstack/echo"
+)
+
 // Controller example
 type Controller struct {
+	// MaxImageSize is the maximum size of an uploaded image in bytes.
+	MaxImageSize int64
+	// MaxImagePixels is the maximum width times height of an uploaded image.
+	MaxImagePixels int
+	// ThumbnailSizes are generated for every uploaded image.
+	ThumbnailSizes []imageutil.Size
+	// Tokens issues the JWTs of the admin endpoints.
+	Tokens *token.Manager
+	// Policy authorizes moving bottles to another account; nil allows any move.
+	Policy *rbac.Policy
+	// Spec is the enforced API spec the routes are compared with.
+	Spec *spec.Document
+	// Routes lists the registered routes, e.g. echo.Echo.Routes.
+	Routes func() []*echo.Route
+
+	accounts model.AccountStore
+	bottles  model.BottleStore
+	images   model.ImageStore
+	blobs    blob.Store
+	keys     model.APIKeyStore
 }
 
 // NewController example
-func NewController() *Controller {
-	return &Controller{}
+func NewController(accounts model.AccountStore, bottles model.BottleStore, images model.ImageStore, blobs blob.Store, keys model.APIKeyStore) *Controller {
+	return &Controller{
+		MaxImageSize:   imageutil.DefaultMaxSize,
+		MaxImagePixels: imageutil.DefaultMaxPixels,
+		ThumbnailSizes: imageutil.DefaultSizes,
+		accounts:       accounts,
+		bottles:        bottles,
+		images:         images,
+		blobs:          blobs,
+		keys:           keys,
+	}
 }
 
 // Message example
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/hexaforce/swagger-echo/auth"
 	"github.com/labstack/echo"
 )
 
@@ -13,11 +14,11 @@ import (
 // @Description do ping
 // @Tags example
 // @Accept json
-// @Produce json
+// @Produce plain
 // @Success 200 {string} string "pong"
-// @Failure 400 {string} string "ok"
-// @Failure 404 {string} string "ok"
-// @Failure 500 {string} string "ok"
+// @Failure 400 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
 // @Router /examples/ping [get]
 func (c *Controller) PingExample(ctx echo.Context) error {
 	return ctx.String(http.StatusOK, "pong")
@@ -28,22 +29,22 @@ func (c *Controller) PingExample(ctx echo.Context) error {
 // @Description plus
 // @Tags example
 // @Accept json
-// @Produce json
+// @Produce plain
 // @Param val1 query int true "used for calc"
 // @Param val2 query int true "used for calc"
 // @Success 200 {integer} integer "answer"
-// @Failure 400 {string} string "ok"
-// @Failure 404 {string} string "ok"
-// @Failure 500 {string} string "ok"
+// @Failure 400 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
 // @Router /examples/calc [get]
 func (c *Controller) CalcExample(ctx echo.Context) error {
 	val1, err := strconv.Atoi(ctx.QueryParam("val1"))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 	val2, err := strconv.Atoi(ctx.QueryParam("val2"))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 	ans := val1 + val2
 	return ctx.String(http.StatusOK, fmt.Sprintf("%d", ans))
@@ -54,22 +55,22 @@ func (c *Controller) CalcExample(ctx echo.Context) error {
 // @Description path params
 // @Tags example
 // @Accept json
-// @Produce json
+// @Produce plain
 // @Param group_id path int true "Group ID"
 // @Param account_id path int true "Account ID"
 // @Success 200 {string} string "answer"
-// @Failure 400 {string} string "ok"
-// @Failure 404 {string} string "ok"
-// @Failure 500 {string} string "ok"
+// @Failure 400 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
 // @Router /examples/groups/{group_id}/accounts/{account_id} [get]
 func (c *Controller) PathParamsExample(ctx echo.Context) error {
-	groupID, err := strconv.Atoi(ctx.Param("group_id"))
+	groupID, err := intParam(ctx, "group_id")
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return err
 	}
-	accountID, err := strconv.Atoi(ctx.Param("account_id"))
+	accountID, err := intParam(ctx, "account_id")
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error)
+		return err
 	}
 	return ctx.String(http.StatusOK, fmt.Sprintf("group_id=%d account_id=%d", groupID, accountID))
 }
@@ -79,12 +80,12 @@ func (c *Controller) PathParamsExample(ctx echo.Context) error {
 // @Description custome header
 // @Tags example
 // @Accept json
-// @Produce json
+// @Produce plain
 // @Param Authorization header string true "Authentication header"
 // @Success 200 {string} string "answer"
-// @Failure 400 {string} string "ok"
-// @Failure 404 {string} string "ok"
-// @Failure 500 {string} string "ok"
+// @Failure 400 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
 // @Router /examples/header [get]
 func (c *Controller) HeaderExample(ctx echo.Context) error {
 	return ctx.String(http.StatusOK, ctx.Request().Header.Get("Authorization"))
@@ -98,14 +99,17 @@ func (c *Controller) HeaderExample(ctx echo.Context) error {
 // @Produce json
 // @Param Authorization header string true "Authentication header"
 // @Success 200 {string} string "answer"
-// @Failure 400 {string} string "ok"
-// @Failure 404 {string} string "ok"
-// @Failure 500 {string} string "ok"
+// @Failure 400 {object} httputil.Problem
+// @Failure 401 {object} httputil.Problem
+// @Failure 403 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
 // @Security ApiKeyAuth
 // @Security OAuth2Implicit[admin, write]
 // @Router /examples/securities [get]
 func (c *Controller) SecuritiesExample(ctx echo.Context) error {
-	return nil
+	admin, _ := auth.Admin(ctx)
+	return ctx.JSON(http.StatusOK, admin.Name)
 }
 
 // AttributeExample godoc
@@ -113,7 +117,7 @@ func (c *Controller) SecuritiesExample(ctx echo.Context) error {
 // @Description attribute
 // @Tags example
 // @Accept json
-// @Produce json
+// @Produce plain
 // @Param enumstring query string false "string enums" Enums(A, B, C)
 // @Param enumint query int false "int enums" Enums(1, 2, 3)
 // @Param enumnumber query number false "int enums" Enums(1.1, 1.2, 1.3)
@@ -121,9 +125,9 @@ func (c *Controller) SecuritiesExample(ctx echo.Context) error {
 // @Param int query int false "int valid" mininum(1) maxinum(10)
 // @Param default query string false "string default" default(A)
 // @Success 200 {string} string "answer"
-// @Failure 400 {string} string "ok"
-// @Failure 404 {string} string "ok"
-// @Failure 500 {string} string "ok"
+// @Failure 400 {object} httputil.Problem
+// @Failure 404 {object} httputil.Problem
+// @Failure 500 {object} httputil.Problem
 // @Router /examples/attribute [get]
 func (c *Controller) AttributeExample(ctx echo.Context) error {
 	return ctx.String(http.StatusOK, fmt.Sprintf("enumstring=%s enumint=%s enumnumber=%s string=%s int=%s default=%s",
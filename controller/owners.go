@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/hexaforce/swagger-echo/auth"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/rbac"
+	"github.com/labstack/echo"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AccountOwner is a rbac.Owner resolving the owner of the account
+// addressed by the id path parameter, an ID or UUID.
+func (c *Controller) AccountOwner(ctx echo.Context) (int, error) {
+	id := ctx.Param("id")
+	var account model.Account
+	var err error
+	if aid, aerr := strconv.Atoi(id); aerr == nil {
+		account, err = c.accounts.AccountOne(aid)
+	} else if u, uerr := uuid.FromString(id); uerr == nil {
+		account, err = c.accounts.AccountByUUID(u)
+	} else {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("id=%s is neither an integer nor a UUID", id))
+	}
+	if err != nil {
+		return 0, err
+	}
+	return account.OwnerID, nil
+}
+
+// BottleOwner is a rbac.Owner resolving the owner of the account of the
+// bottle addressed by the id path parameter. Orphaned bottles have none.
+func (c *Controller) BottleOwner(ctx echo.Context) (int, error) {
+	bid, err := intParam(ctx, "id")
+	if err != nil {
+		return 0, err
+	}
+	bottle, err := c.bottles.BottleOne(bid)
+	if err != nil {
+		return 0, err
+	}
+	if bottle.Account == nil {
+		return 0, nil
+	}
+	return bottle.Account.OwnerID, nil
+}
+
+// BottleAccountOwner is a rbac.Owner resolving the owner of the account
+// named by account_id or account_uuid in a JSON bottle body. The body is left for the handler.
+func (c *Controller) BottleAccountOwner(ctx echo.Context) (int, error) {
+	req := ctx.Request()
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	var addBottle model.AddBottle
+	if err := json.Unmarshal(body, &addBottle); err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := ctx.Validate(&addBottle); err != nil {
+		return 0, err
+	}
+	accountID, err := c.ownerOf(addBottle.AccountID, addBottle.AccountUUID)
+	if err != nil {
+		return 0, err
+	}
+	return c.accountOwner(accountID)
+}
+
+// ownerOf resolves the ID of the account a bottle body names by
+// account_id, account_uuid or both.
+func (c *Controller) ownerOf(accountID int, accountUUID *uuid.UUID) (int, error) {
+	if accountUUID == nil {
+		return accountID, nil
+	}
+	account, err := c.accounts.AccountByUUID(*accountUUID)
+	if err == model.ErrNoRow {
+		return 0, model.ErrOwnerNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if accountID != 0 && accountID != account.ID {
+		return 0, model.ErrOwnerMismatch
+	}
+	return account.ID, nil
+}
+
+// accountOwner resolves the owner of the account a bottle is moved or added to.
+func (c *Controller) accountOwner(accountID int) (int, error) {
+	account, err := c.accounts.AccountOne(accountID)
+	if err == model.ErrNoRow {
+		return 0, model.ErrOwnerNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return account.OwnerID, nil
+}
+
+// authorizeAccount checks that the principal holds perm on the account a
+// bottle is moved to. Without a Policy any move is allowed.
+func (c *Controller) authorizeAccount(ctx echo.Context, perm rbac.Permission, accountID int) error {
+	if c.Policy == nil {
+		return nil
+	}
+	admin, _ := auth.Admin(ctx)
+	return c.Policy.Check(admin, perm, func() (int, error) {
+		return c.accountOwner(accountID)
+	})
+}
+
+// principalID is the ID of the authenticated principal, 0 for none.
+func principalID(ctx echo.Context) int {
+	admin, _ := auth.Admin(ctx)
+	return admin.ID
+}
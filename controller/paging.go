@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/labstack/echo"
+)
+
+// listOptions reads the limit, offset, cursor and sort query parameters.
+func listOptions(ctx echo.Context, sortFields ...string) (model.ListOptions, error) {
+	var opts model.ListOptions
+	var err error
+	if v := ctx.QueryParam("limit"); v != "" {
+		if opts.Limit, err = strconv.Atoi(v); err != nil || opts.Limit < 0 {
+			return opts, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit=%s must be a non-negative integer", v))
+		}
+	}
+	if v := ctx.QueryParam("offset"); v != "" {
+		if opts.Offset, err = strconv.Atoi(v); err != nil || opts.Offset < 0 {
+			return opts, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("offset=%s must be a non-negative integer", v))
+		}
+	}
+	opts.Cursor = ctx.QueryParam("cursor")
+	if opts.Sort, err = model.ParseSort(ctx.QueryParam("sort"), sortFields...); err != nil {
+		return opts, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return opts, nil
+}
+
+// setPageHeaders writes the X-Total-Count and Link headers for a page of n items.
+func setPageHeaders(ctx echo.Context, opts model.ListOptions, page model.Page, n int) {
+	h := ctx.Response().Header()
+	h.Set("X-Total-Count", strconv.Itoa(page.Total))
+
+	var links []string
+	link := func(rel string, set map[string]string) {
+		u := *ctx.Request().URL
+		q := u.Query()
+		for k, v := range set {
+			if v == "" {
+				q.Del(k)
+			} else {
+				q.Set(k, v)
+			}
+		}
+		u.RawQuery = q.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel))
+	}
+	switch {
+	case opts.Cursor != "":
+		link("first", map[string]string{"cursor": "", "offset": ""})
+		if page.Next != "" {
+			link("next", map[string]string{"cursor": page.Next})
+		}
+	case opts.Limit > 0:
+		link("first", map[string]string{"offset": ""})
+		if opts.Offset > 0 {
+			prev := opts.Offset - opts.Limit
+			if prev < 0 {
+				prev = 0
+			}
+			link("prev", map[string]string{"offset": strconv.Itoa(prev)})
+		}
+		if opts.Offset+n < page.Total {
+			link("next", map[string]string{"offset": strconv.Itoa(opts.Offset + opts.Limit)})
+		}
+		if page.Total > 0 {
+			link("last", map[string]string{"offset": strconv.Itoa((page.Total - 1) / opts.Limit * opts.Limit)})
+		}
+	}
+	if len(links) > 0 {
+		h.Set("Link", strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	uuid "github.com/satori/go.uuid"
+)
+
+// intParam reads an integer path parameter.
+func intParam(ctx echo.Context, name string) (int, error) {
+	v := ctx.Param(name)
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s=%s is not an integer", name, v))
+	}
+	return i, nil
+}
+
+// uuidParam reads a UUID path parameter.
+func uuidParam(ctx echo.Context, name string) (uuid.UUID, error) {
+	v := ctx.Param(name)
+	u, err := uuid.FromString(v)
+	if err != nil {
+		return uuid.UUID{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s=%s is not a UUID", name, v))
+	}
+	return u, nil
+}
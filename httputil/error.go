@@ -1,18 +1,145 @@
 package httputil
 
-import "github.com/labstack/echo"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 
-// NewError example
+	"github.com/hexaforce/swagger-echo/validate"
+	"github.com/labstack/echo"
+)
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details.
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem example
+type Problem struct {
+	Type      string `json:"type" example:"about:blank"`
+	Title     string `json:"title" example:"Not Found"`
+	Status    int    `json:"status" example:"404"`
+	Detail    string `json:"detail,omitempty" example:"no rows in result set"`
+	Instance  string `json:"instance,omitempty" example:"/api/v1/accounts/9"`
+	RequestID string `json:"request_id,omitempty" example:"vN3bKx8gq4Tz1wYcR7uLdPa2sJfE6hMo"`
+	// Permission and Roles describe a refused permission, see package rbac.
+	Permission string   `json:"permission,omitempty" example:"accounts:delete"`
+	Roles      []string `json:"roles,omitempty" example:"reader"`
+	// Errors lists the fields of a request body that failed validation.
+	Errors []validate.FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem returns a problem of status with its standard title.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+// HTTPError is the former name of Problem, kept for existing callers.
+//
+// Deprecated: use Problem.
+type HTTPError = Problem
+
+// StatusError is implemented by errors that know the status of their
+// problem, such as the errors of the model, blob, imageutil, auth and token
+// packages.
+type StatusError interface {
+	error
+	Status() int
+}
+
+// ProblemOf converts err to a problem. Problems are taken as they are,
+// echo HTTP errors keep their code and message and a StatusError gets its
+// own status. Any other error is an internal server error whose
+// detail is withheld from the client.
+func ProblemOf(err error) *Problem {
+	switch e := err.(type) {
+	case *Problem:
+		p := *e
+		if p.Type == "" {
+			p.Type = "about:blank"
+		}
+		if p.Title == "" {
+			p.Title = http.StatusText(p.Status)
+		}
+		return &p
+	case *echo.HTTPError:
+		detail := ""
+		switch m := e.Message.(type) {
+		case string:
+			detail = m
+		case error:
+			detail = m.Error()
+		case nil:
+		default:
+			detail = fmt.Sprint(m)
+		}
+		if detail == http.StatusText(e.Code) {
+			detail = ""
+		}
+		return NewProblem(e.Code, detail)
+	case validate.Errors:
+		p := NewProblem(http.StatusBadRequest, "request body is invalid")
+		p.Errors = e
+		return p
+	}
+	var se StatusError
+	if errors.As(err, &se) {
+		return NewProblem(se.Status(), err.Error())
+	}
+	return NewProblem(http.StatusInternalServerError, "")
+}
+
+// ErrorHandler is an echo.HTTPErrorHandler answering every error with
+// application/problem+json. The instance is the request path and the
+// request ID is taken from the X-Request-ID header. Internal server errors
+// are logged and their detail is withheld.
+func ErrorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+	p := ProblemOf(err)
+	if p.Status >= http.StatusInternalServerError {
+		ctx.Logger().Error(err)
+		p.Detail = ""
+	}
+	req := ctx.Request()
+	p.Instance = req.URL.Path
+	p.RequestID = ctx.Response().Header().Get(echo.HeaderXRequestID)
+	if p.RequestID == "" {
+		p.RequestID = req.Header.Get(echo.HeaderXRequestID)
+	}
+	if err := NewProblemError(ctx, p); err != nil {
+		ctx.Logger().Error(err)
+	}
+}
+
+// NewError writes err as a problem of status. Unlike ErrorHandler it
+// neither withholds the detail of internal server errors nor sets the
+// instance and request ID.
+//
+// Deprecated: return the error from the handler for ErrorHandler to
+// answer, or write a problem with NewProblemError.
 func NewError(ctx echo.Context, status int, err error) {
-	er := HTTPError{
-		Code:    status,
-		Message: err.Error(),
+	detail := ""
+	if err != nil {
+		detail = err.Error()
+	}
+	if err := NewProblemError(ctx, NewProblem(status, detail)); err != nil {
+		ctx.Logger().Error(err)
 	}
-	ctx.JSON(status, er)
 }
 
-// HTTPError example
-type HTTPError struct {
-	Code    int    `json:"code" example:"400"`
-	Message string `json:"message" example:"status bad request"`
+// NewProblemError writes p as the response.
+func NewProblemError(ctx echo.Context, p *Problem) error {
+	if ctx.Request().Method == http.MethodHead {
+		return ctx.NoContent(p.Status)
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return ctx.Blob(p.Status, MIMEProblemJSON, b)
 }
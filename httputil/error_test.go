@@ -0,0 +1,59 @@
+package httputil_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hexaforce/swagger-echo/auth"
+	"github.com/hexaforce/swagger-echo/blob"
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/hexaforce/swagger-echo/imageutil"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/token"
+	"github.com/labstack/echo"
+)
+
+func TestProblemOf(t *testing.T) {
+	for _, tc := range []struct {
+		err        error
+		wantStatus int
+		wantDetail string
+	}{
+		{model.ErrNoRow, http.StatusNotFound, "no rows in result set"},
+		{model.ErrOwnerMismatch, http.StatusBadRequest, model.ErrOwnerMismatch.Error()},
+		{model.ErrAccountHasBottles, http.StatusConflict, model.ErrAccountHasBottles.Error()},
+		{&model.FilterError{Pos: 3, Msg: "unexpected end"}, http.StatusBadRequest, "filter: unexpected end at offset 3"},
+		{blob.ErrNotExist, http.StatusNotFound, blob.ErrNotExist.Error()},
+		{imageutil.ErrTooLarge, http.StatusRequestEntityTooLarge, imageutil.ErrTooLarge.Error()},
+		{auth.ErrInsufficientScope, http.StatusForbidden, auth.ErrInsufficientScope.Error()},
+		{token.ErrRevoked, http.StatusUnauthorized, token.ErrRevoked.Error()},
+		{fmt.Errorf("account 9: %w", model.ErrNoRow), http.StatusNotFound, "account 9: no rows in result set"},
+		{echo.NewHTTPError(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed, ""},
+		{httputil.NewProblem(http.StatusTeapot, "short and stout"), http.StatusTeapot, "short and stout"},
+		{fmt.Errorf("disk on fire"), http.StatusInternalServerError, ""},
+	} {
+		p := httputil.ProblemOf(tc.err)
+		if p.Status != tc.wantStatus || p.Detail != tc.wantDetail || p.Title != http.StatusText(tc.wantStatus) {
+			t.Errorf("ProblemOf(%v) = %d %q %q, want %d %q", tc.err, p.Status, p.Title, p.Detail, tc.wantStatus, tc.wantDetail)
+		}
+	}
+}
+
+func TestNewError(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/accounts/9", nil), rec)
+	httputil.NewError(ctx, http.StatusNotFound, errors.New("no such account"))
+
+	var p httputil.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotFound || rec.Header().Get(echo.HeaderContentType) != httputil.MIMEProblemJSON || p.Status != http.StatusNotFound || p.Detail != "no such account" {
+		t.Errorf("NewError wrote %d %s %s", rec.Code, rec.Header().Get(echo.HeaderContentType), rec.Body)
+	}
+}
@@ -0,0 +1,200 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Defaults for the upload limits. A decoded image takes up to 4 bytes per
+// pixel, so DefaultMaxPixels bounds an upload to about 64MB of memory.
+const (
+	DefaultMaxSize   = 5 << 20
+	DefaultMaxPixels = 16000000
+)
+
+var (
+	// ErrUnsupportedType is returned for content that is not a PNG, JPEG or GIF image.
+	ErrUnsupportedType error = &statusError{"image must be PNG, JPEG or GIF", http.StatusUnsupportedMediaType}
+	// ErrTooLarge is returned for images over the size or pixel limit.
+	ErrTooLarge error = &statusError{"image is too large", http.StatusRequestEntityTooLarge}
+	// ErrCorrupt is returned for content that looks like an image but cannot be decoded.
+	ErrCorrupt error = &statusError{"image is corrupt", http.StatusBadRequest}
+)
+
+// statusError is an error answered with the HTTP status it carries.
+type statusError struct {
+	msg    string
+	status int
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// Status is the HTTP status the error is answered with.
+func (e *statusError) Status() int {
+	return e.status
+}
+
+// Types are the content types accepted for images.
+var Types = []string{"image/png", "image/jpeg", "image/gif"}
+
+// Size is a named thumbnail size; the longer side of the thumbnail is at most Max pixels.
+type Size struct {
+	Name string
+	Max  int
+}
+
+// DefaultSizes are the thumbnail sizes used when none are configured.
+var DefaultSizes = []Size{{Name: "thumb", Max: 128}, {Name: "medium", Max: 512}}
+
+// ParseSizes parses a comma separated list of name=max pairs such as "thumb=128,medium=512".
+func ParseSizes(s string) ([]Size, error) {
+	sizes := []Size{}
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("thumbnail size %q must be name=max", f)
+		}
+		max, err := strconv.Atoi(kv[1])
+		if err != nil || max <= 0 {
+			return nil, fmt.Errorf("thumbnail size %q must have a positive max", f)
+		}
+		for _, v := range sizes {
+			if v.Name == kv[0] {
+				return nil, fmt.Errorf("thumbnail size %q is given twice", kv[0])
+			}
+		}
+		sizes = append(sizes, Size{Name: kv[0], Max: max})
+	}
+	return sizes, nil
+}
+
+// Limits bounds what Decode accepts.
+type Limits struct {
+	// MaxSize is the maximum number of bytes.
+	MaxSize int64
+	// MaxPixels is the maximum width times height, guarding against decompression bombs.
+	MaxPixels int
+}
+
+// Decode reads at most l.MaxSize bytes from r, sniffs the content type from the
+// content and decodes it. It returns the raw content along with the image.
+func Decode(r io.Reader, l Limits) ([]byte, string, image.Image, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, l.MaxSize+1))
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if int64(len(data)) > l.MaxSize {
+		return nil, "", nil, ErrTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if !supported(contentType) {
+		return nil, "", nil, ErrUnsupportedType
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", nil, ErrCorrupt
+	}
+	if cfg.Width*cfg.Height > l.MaxPixels {
+		return nil, "", nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", nil, ErrCorrupt
+	}
+	return data, contentType, img, nil
+}
+
+func supported(contentType string) bool {
+	for _, t := range Types {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// ThumbnailType is the content type thumbnails of an image of contentType are encoded in.
+// JPEG stays JPEG; everything else becomes PNG so that transparency is kept.
+func ThumbnailType(contentType string) string {
+	if contentType == "image/jpeg" {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// Thumbnail scales img down so that its longer side is at most max pixels,
+// averaging the source pixels covered by each thumbnail pixel. The source is
+// read in place rather than copied, so only the thumbnail is allocated.
+// Images that are already small enough are returned as they are.
+func Thumbnail(img image.Image, max int) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	w, h := sw, sh
+	if w > max || h > max {
+		if w >= h {
+			w, h = max, sh*max/sw
+		} else {
+			w, h = sw*max/sh, max
+		}
+		if w < 1 {
+			w = 1
+		}
+		if h < 1 {
+			h = 1
+		}
+	}
+	if w == sw && h == sh {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := b.Min.Y+y*sh/h, b.Min.Y+(y+1)*sh/h
+		for x := 0; x < w; x++ {
+			x0, x1 := b.Min.X+x*sw/w, b.Min.X+(x+1)*sw/w
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += pr >> 8
+					g += pg >> 8
+					bl += pb >> 8
+					a += pa >> 8
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// Encode writes img to w in the given content type.
+func Encode(w io.Writer, img image.Image, contentType string) error {
+	switch contentType {
+	case "image/jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	case "image/png":
+		return png.Encode(w, img)
+	case "image/gif":
+		return gif.Encode(w, img, nil)
+	}
+	return ErrUnsupportedType
+}
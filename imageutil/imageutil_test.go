@@ -0,0 +1,51 @@
+package imageutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestThumbnail(t *testing.T) {
+	// A 4x2 image with bounds not at the origin: the left half black, the
+	// right half white.
+	src := image.NewNRGBA(image.Rect(10, 10, 14, 12))
+	for y := 10; y < 12; y++ {
+		for x := 10; x < 14; x++ {
+			c := color.NRGBA{A: 255}
+			if x >= 12 {
+				c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+	thumb := Thumbnail(src, 2)
+	if got := thumb.Bounds(); got != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("bounds %v, want 2x1", got)
+	}
+	for x, want := range []uint8{0, 255} {
+		if r, _, _, a := thumb.At(x, 0).RGBA(); uint8(r>>8) != want || uint8(a>>8) != 255 {
+			t.Errorf("pixel %d is %v, want gray %d", x, thumb.At(x, 0), want)
+		}
+	}
+	if small := Thumbnail(src, 8); small != image.Image(src) {
+		t.Errorf("an image within max was scaled")
+	}
+}
+
+func TestDecodeMaxPixels(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 100, 100))); err != nil {
+		t.Fatal(err)
+	}
+	l := Limits{MaxSize: DefaultMaxSize, MaxPixels: 100 * 100}
+	if _, contentType, _, err := Decode(bytes.NewReader(buf.Bytes()), l); err != nil || contentType != "image/png" {
+		t.Fatalf("Decode = %q, %v", contentType, err)
+	}
+	l.MaxPixels--
+	if _, _, _, err := Decode(bytes.NewReader(buf.Bytes()), l); err != ErrTooLarge {
+		t.Errorf("Decode over the pixel limit = %v, want %v", err, ErrTooLarge)
+	}
+}
@@ -1,12 +1,10 @@
 package main
 
 import (
-	"errors"
-	"net/http"
+	"flag"
 
-	"github.com/hexaforce/swagger-echo/controller"
+	"github.com/hexaforce/swagger-echo/server"
 	"github.com/labstack/echo"
-	"github.com/labstack/echo/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
@@ -22,7 +20,7 @@ import (
 // @license.name Apache 2.0
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 
-// @host localhost:8080
+// @host localhost:1323
 // @BasePath /api/v1
 
 // @securityDefinitions.basic BasicAuth
@@ -32,77 +30,55 @@ import (
 // @name Authorization
 
 // @securitydefinitions.oauth2.application OAuth2Application
-// @tokenUrl https://example.com/oauth/token
+// @tokenUrl http://localhost:1323/oauth/token
 // @scope.write Grants write access
 // @scope.admin Grants read and write access to administrative information
 
 // @securitydefinitions.oauth2.implicit OAuth2Implicit
-// @authorizationUrl https://example.com/oauth/authorize
+// @authorizationUrl http://localhost:1323/oauth/authorize
 // @scope.write Grants write access
 // @scope.admin Grants read and write access to administrative information
 
 // @securitydefinitions.oauth2.password OAuth2Password
-// @tokenUrl https://example.com/oauth/token
+// @tokenUrl http://localhost:1323/oauth/token
 // @scope.read Grants read access
 // @scope.write Grants write access
 // @scope.admin Grants read and write access to administrative information
 
 // @securitydefinitions.oauth2.accessCode OAuth2AccessCode
-// @tokenUrl https://example.com/oauth/token
-// @authorizationUrl https://example.com/oauth/authorize
+// @tokenUrl http://localhost:1323/oauth/token
+// @authorizationUrl http://localhost:1323/oauth/authorize
 // @scope.admin Grants read and write access to administrative information
 
 func main() {
+	cfg := server.DefaultConfig()
+	flag.StringVar(&cfg.DBPath, "db", cfg.DBPath, "SQLite database file (in-memory store when empty)")
+	flag.StringVar(&cfg.BlobDir, "blob-dir", cfg.BlobDir, "directory uploaded account images are stored in")
+	flag.Int64Var(&cfg.MaxImageSize, "max-image-size", cfg.MaxImageSize, "maximum size of an uploaded account image in bytes")
+	flag.StringVar(&cfg.ThumbnailSizes, "thumbnail-sizes", cfg.ThumbnailSizes, "comma separated name=max thumbnail sizes generated for uploaded images")
+	flag.StringVar(&cfg.AdminKey, "admin-key", cfg.AdminKey, "bootstrap API key of the admin user, e.g. to create the first stored keys (disabled when empty)")
+	flag.StringVar(&cfg.AdminPassword, "admin-password", cfg.AdminPassword, "Basic auth password of the admin user (disabled when empty)")
+	flag.StringVar(&cfg.OAuthClientSecret, "oauth-client-secret", cfg.OAuthClientSecret, "secret of the confidential OAuth2 client celler (disabled when empty)")
+	flag.StringVar(&cfg.JWTAlg, "jwt-alg", cfg.JWTAlg, "signing algorithm of admin JWTs: HS256 or RS256")
+	flag.StringVar(&cfg.JWTKey, "jwt-key", cfg.JWTKey, "file with the HS256 secret or the PEM RS256 private key (random HS256 secret when empty)")
+	flag.StringVar(&cfg.RBACPolicy, "rbac-policy", cfg.RBACPolicy, "JSON file with the roles and permissions of the account and bottle routes (admin, owner and reader when empty)")
+	flag.StringVar(&cfg.Addr, "addr", cfg.Addr, "address the server listens on, also the host of the Swagger UI OAuth2 redirect URI")
+	flag.StringVar(&cfg.Spec, "spec", cfg.Spec, "swagger 2.0 document the security requirements and request rules are read from (the generated docs when empty)")
+	flag.BoolVar(&cfg.ValidateRequests, "validate-requests", cfg.ValidateRequests, "check path, query, header and body parameters against the spec")
+	flag.StringVar(&cfg.ValidateResponses, "validate-responses", cfg.ValidateResponses, "check responses against the spec and log (log) or answer 500 to (fail) violations, for development and tests (off when empty)")
+	flag.BoolVar(&cfg.StrictRoutes, "strict-routes", cfg.StrictRoutes, "refuse to start when the routes and the spec drift apart (the drift is logged otherwise)")
+	flag.BoolVar(&cfg.Mock, "mock", cfg.Mock, "answer every operation of the spec with responses synthesized from its examples instead of the handlers")
+	flag.BoolVar(&cfg.MockState, "mock-state", cfg.MockState, "keep the resources created in mock mode in memory, so that they can be listed, shown, updated and deleted")
+	flag.StringVar(&cfg.DeletePolicy, "delete-policy", cfg.DeletePolicy, "what deleting an account does to its bottles: restrict, cascade or orphan")
+	flag.Parse()
 
 	// Echo instance
 	e := echo.New()
-
-	// Middleware
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
-
-	// Controller
-	c := controller.NewController()
-
-	// Routes
-	v1 := e.Group("/api/v1")
-	{
-		accounts := v1.Group("/accounts")
-		{
-			accounts.GET(":id", c.ShowAccount)
-			accounts.GET("", c.ListAccounts)
-			accounts.POST("", c.AddAccount)
-			accounts.DELETE(":id", c.DeleteAccount)
-			accounts.PATCH(":id", c.UpdateAccount)
-			accounts.POST(":id/images", c.UploadAccountImage)
-		}
-		bottles := v1.Group("/bottles")
-		{
-			bottles.GET(":id", c.ShowBottle)
-			bottles.GET("", c.ListBottles)
-		}
-		admin := v1.Group("/admin")
-		{
-			admin.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
-				return func(c echo.Context) error {
-					if len(c.Request().Header.Get("Authorization")) == 0 {
-						return echo.NewHTTPError(http.StatusUnauthorized, errors.New("Authorization is required Header"))
-					}
-					return nil
-				}
-			})
-			admin.POST("/auth", c.Auth)
-		}
-		examples := v1.Group("/examples")
-		{
-			examples.GET("ping", c.PingExample)
-			examples.GET("calc", c.CalcExample)
-			examples.GET("groups/:group_id/accounts/:account_id", c.PathParamsExample)
-			examples.GET("header", c.HeaderExample)
-			examples.GET("securities", c.SecuritiesExample)
-			examples.GET("attribute", c.AttributeExample)
-		}
+	srv, err := server.New(e, cfg)
+	if err != nil {
+		e.Logger.Fatal(err)
 	}
+	defer srv.Close()
 
 	// swaggerUI
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
@@ -113,5 +89,5 @@ func main() {
 	*/
 
 	// Start server
-	e.Logger.Fatal(e.Start(":1323"))
+	e.Logger.Fatal(e.Start(cfg.Addr))
 }
@@ -0,0 +1,408 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/spec"
+	"github.com/labstack/echo"
+	uuid "github.com/satori/go.uuid"
+)
+
+// HeaderXMockStatus picks the declared status code of a mocked response.
+const HeaderXMockStatus = "X-Mock-Status"
+
+type object = map[string]interface{}
+
+// Server answers every operation of a swagger document with responses
+// synthesized from the example, enum, default and format of its schemas.
+type Server struct {
+	// Stateful keeps the resources created through collections, e.g.
+	// POST /accounts, in memory so that they are listed, shown, updated
+	// and deleted through the collection and its item path, e.g.
+	// /accounts/{id}. Each collection starts with the example of its item
+	// schema. Other operations stay stateless.
+	Stateful bool
+
+	doc *spec.Document
+	// itemSchemas maps the path of each collection of the document to the
+	// schema of its items.
+	itemSchemas map[string]*spec.Schema
+	mu          sync.Mutex
+	collections map[string]*collection
+}
+
+// collection is a stateful collection of resources keyed by their id.
+type collection struct {
+	ids   []string
+	items map[string]object
+	next  int
+}
+
+// kind is how an operation takes part in the stateful simulation.
+type kind int
+
+const (
+	stateless kind = iota
+	list
+	create
+	item
+)
+
+// New returns a stateless Server for doc.
+func New(doc *spec.Document) *Server {
+	return &Server{doc: doc, itemSchemas: map[string]*spec.Schema{}, collections: map[string]*collection{}}
+}
+
+// Register routes every operation of the document on g, which is mounted at
+// the basePath of the document.
+func (s *Server) Register(g *echo.Group) {
+	ops := s.doc.Operations()
+	paths := map[string]bool{}
+	for _, op := range ops {
+		paths[op.Path] = true
+	}
+	// Collections are paths with operations on their items as well.
+	parents := map[string]bool{}
+	for _, op := range ops {
+		if i := strings.LastIndex(op.Path, "/"); isParam(op.Path[i+1:]) && paths[op.Path[:i]] {
+			parents[op.Path[:i]] = true
+		}
+	}
+	for _, op := range ops {
+		k := stateless
+		i := strings.LastIndex(op.Path, "/")
+		switch {
+		case isParam(op.Path[i+1:]) && parents[op.Path[:i]] && op.Method != http.MethodPost:
+			k = item
+		case parents[op.Path] && op.Method == http.MethodGet:
+			k = list
+			if schema := s.successSchema(op); schema != nil && schema.Type == "array" {
+				s.itemSchemas[op.Path] = schema.Items
+			}
+		case parents[op.Path] && op.Method == http.MethodPost:
+			k = create
+			if _, ok := s.itemSchemas[op.Path]; !ok {
+				s.itemSchemas[op.Path] = s.successSchema(op)
+			}
+		}
+		g.Add(op.Method, spec.EchoPath("", op.Path), s.handler(op, k))
+	}
+}
+
+// successSchema is the resolved schema of the first declared success
+// response of op, nil when it has none.
+func (s *Server) successSchema(op *spec.Operation) *spec.Schema {
+	status, err := s.status(op, "")
+	if err != nil || op.Responses[strconv.Itoa(status)] == nil {
+		return nil
+	}
+	return s.doc.Resolve(op.Responses[strconv.Itoa(status)].Schema)
+}
+
+func (s *Server) handler(op *spec.Operation, k kind) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		status, err := s.status(op, ctx.Request().Header.Get(HeaderXMockStatus))
+		if err != nil {
+			return err
+		}
+		if s.Stateful && k != stateless && ctx.Request().Header.Get(HeaderXMockStatus) == "" {
+			if handled, err := s.simulate(ctx, op, k, status); handled {
+				return err
+			}
+		}
+		return s.respond(ctx, op, status, nil)
+	}
+}
+
+// status is the status picked by the X-Mock-Status header, or the first
+// declared success status.
+func (s *Server) status(op *spec.Operation, picked string) (int, error) {
+	if picked != "" {
+		code, err := strconv.Atoi(picked)
+		if _, ok := op.Responses[picked]; err != nil || !ok {
+			return 0, httputil.NewProblem(http.StatusBadRequest, "status "+picked+" is not declared by "+op.Method+" "+op.Path)
+		}
+		return code, nil
+	}
+	var codes []int
+	for c := range op.Responses {
+		if code, err := strconv.Atoi(c); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return http.StatusOK, nil
+	}
+	sort.Ints(codes)
+	return codes[0], nil
+}
+
+// respond sends v, or an example of the response schema when v is nil.
+// Problems are answered by the error handler like those of the handlers.
+func (s *Server) respond(ctx echo.Context, op *spec.Operation, status int, v interface{}) error {
+	r := op.Responses[strconv.Itoa(status)]
+	if r == nil {
+		r = op.Responses["default"]
+	}
+	var schema *spec.Schema
+	if r != nil {
+		schema = s.doc.Resolve(r.Schema)
+	}
+	if schema == nil || status == http.StatusNoContent || status == http.StatusNotModified {
+		return ctx.NoContent(status)
+	}
+	if status >= http.StatusBadRequest && schema.Properties["status"] != nil && schema.Properties["title"] != nil {
+		return httputil.NewProblem(status, "mock response")
+	}
+	mediaType := echo.MIMEApplicationJSON
+	if len(op.Produces) > 0 {
+		mediaType = op.Produces[0]
+	}
+	if schema.Type == "file" {
+		if mediaType == echo.MIMEApplicationJSON {
+			mediaType = echo.MIMEOctetStream
+		}
+		return ctx.Blob(status, mediaType, nil)
+	}
+	if v == nil {
+		v = s.example(schema, 0)
+	}
+	if mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json") {
+		return ctx.JSON(status, v)
+	}
+	return ctx.Blob(status, mediaType, []byte(fmt.Sprint(v)))
+}
+
+// simulate answers a collection or item operation from the kept resources;
+// items that were not created are not found. It reports false for
+// collections whose responses are not resources.
+func (s *Server) simulate(ctx echo.Context, op *spec.Operation, k kind, status int) (bool, error) {
+	r := op.Responses[strconv.Itoa(status)]
+	var schema *spec.Schema
+	if r != nil {
+		schema = s.doc.Resolve(r.Schema)
+	}
+	path := strings.TrimSuffix(ctx.Request().URL.Path, "/")
+	switch k {
+	case list:
+		if schema == nil || schema.Type != "array" || !isObject(s.doc.Resolve(schema.Items)) {
+			return false, nil
+		}
+		s.mu.Lock()
+		c := s.collection(path, op.Path)
+		items := make([]interface{}, 0, len(c.ids))
+		for _, id := range c.ids {
+			items = append(items, copyObject(c.items[id]))
+		}
+		s.mu.Unlock()
+		return true, s.respond(ctx, op, status, items)
+	case create:
+		if !isObject(schema) {
+			return false, nil
+		}
+		body, err := bodyOf(ctx)
+		if err != nil {
+			return true, err
+		}
+		v, ok := s.example(schema, 0).(object)
+		if !ok {
+			v = object{}
+		}
+		v = copyObject(v)
+		merge(v, body, schema)
+		s.freshUUIDs(v, body, schema)
+		s.mu.Lock()
+		c := s.collection(path, op.Path)
+		c.next++
+		id := strconv.Itoa(c.next)
+		if p := s.doc.Resolve(schema.Properties["id"]); p != nil && p.Type == "string" {
+			id = uuid.Must(uuid.NewV4()).String()
+			v["id"] = id
+		} else if p != nil {
+			v["id"] = c.next
+		}
+		c.ids = append(c.ids, id)
+		c.items[id] = v
+		v = copyObject(v)
+		s.mu.Unlock()
+		return true, s.respond(ctx, op, status, v)
+	}
+	i := strings.LastIndex(path, "/")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.collection(path[:i], op.Path[:strings.LastIndex(op.Path, "/")])
+	id := path[i+1:]
+	v, ok := c.items[id]
+	if !ok {
+		return true, model.ErrNoRow
+	}
+	switch op.Method {
+	case http.MethodDelete:
+		delete(c.items, id)
+		for j := range c.ids {
+			if c.ids[j] == id {
+				c.ids = append(c.ids[:j], c.ids[j+1:]...)
+				break
+			}
+		}
+		return true, s.respond(ctx, op, status, nil)
+	case http.MethodPut, http.MethodPatch:
+		body, err := bodyOf(ctx)
+		if err != nil {
+			return true, err
+		}
+		merge(v, body, s.doc.Resolve(s.itemSchema(op, status)))
+	}
+	if !isObject(schema) {
+		return true, s.respond(ctx, op, status, nil)
+	}
+	return true, s.respond(ctx, op, status, copyObject(v))
+}
+
+// itemSchema is the schema of the response, or of the body when the
+// response has none.
+func (s *Server) itemSchema(op *spec.Operation, status int) *spec.Schema {
+	if r := op.Responses[strconv.Itoa(status)]; r != nil && r.Schema != nil {
+		return r.Schema
+	}
+	for _, p := range op.Parameters {
+		if p.In == "body" {
+			return p.Schema
+		}
+	}
+	return nil
+}
+
+// collection returns the collection at path, creating it with the example
+// of the items of the document collection at template. s.mu must be held.
+func (s *Server) collection(path, template string) *collection {
+	c, ok := s.collections[path]
+	if ok {
+		return c
+	}
+	c = &collection{items: map[string]object{}}
+	s.collections[path] = c
+	v, ok := s.example(s.itemSchemas[template], 0).(object)
+	if !ok {
+		return c
+	}
+	id := "1"
+	if v["id"] != nil {
+		id = fmt.Sprint(v["id"])
+	}
+	if n, err := strconv.Atoi(id); err == nil {
+		c.next = n
+	}
+	c.ids = append(c.ids, id)
+	c.items[id] = copyObject(v)
+	return c
+}
+
+// freshUUIDs gives the uuid properties of schema that body does not set a
+// new UUID in v, so that created resources do not share the example's.
+func (s *Server) freshUUIDs(v, body object, schema *spec.Schema) {
+	for name, p := range schema.Properties {
+		p = s.doc.Resolve(p)
+		if _, ok := body[name]; ok || p == nil || p.Type != "string" || p.Format != "uuid" {
+			continue
+		}
+		v[name] = uuid.Must(uuid.NewV4()).String()
+	}
+}
+
+// example synthesizes a value of s from its example, enum, default, type
+// and format.
+func (s *Server) example(schema *spec.Schema, depth int) interface{} {
+	schema = s.doc.Resolve(schema)
+	if schema == nil || depth > 8 {
+		return nil
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case schema.Default != nil:
+		return schema.Default
+	}
+	switch schema.Type {
+	case "array":
+		return []interface{}{s.example(schema.Items, depth+1)}
+	case "integer", "number":
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+		return 1
+	case "boolean":
+		return true
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "2019-04-19T12:00:00Z"
+		case "date":
+			return "2019-04-19"
+		case "uuid":
+			return "7d444840-9dc0-11d1-b245-5ffdce74fad2"
+		case "email":
+			return "user@example.com"
+		}
+		return "string"
+	}
+	if schema.Properties == nil {
+		return nil
+	}
+	v := object{}
+	for name, p := range schema.Properties {
+		v[name] = s.example(p, depth+1)
+	}
+	return v
+}
+
+// bodyOf decodes a JSON object body, nil for other bodies.
+func bodyOf(ctx echo.Context) (object, error) {
+	req := ctx.Request()
+	if req.Body == nil || !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return nil, nil
+	}
+	var body object
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return body, nil
+}
+
+// merge copies the properties of schema in body but the id into v.
+func merge(v, body object, schema *spec.Schema) {
+	for name, value := range body {
+		if name == "id" || schema == nil {
+			continue
+		}
+		if _, ok := schema.Properties[name]; ok {
+			v[name] = value
+		}
+	}
+}
+
+func isObject(s *spec.Schema) bool {
+	return s != nil && s.Properties != nil
+}
+
+func isParam(seg string) bool {
+	return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+}
+
+func copyObject(v object) object {
+	c := make(object, len(v))
+	for k, value := range v {
+		c[k] = value
+	}
+	return c
+}
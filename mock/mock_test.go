@@ -0,0 +1,202 @@
+package mock
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/hexaforce/swagger-echo/spec"
+	"github.com/labstack/echo"
+)
+
+const testDoc = `{
+	"basePath": "/api/v1",
+	"paths": {
+		"/accounts": {
+			"get": {"responses": {"200": {"schema": {"type": "array", "items": {"$ref": "#/definitions/Account"}}}}},
+			"post": {
+				"parameters": [{"name": "account", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Account"}}],
+				"responses": {"200": {"schema": {"$ref": "#/definitions/Account"}}}
+			}
+		},
+		"/accounts/{id}": {
+			"get": {"responses": {
+				"200": {"schema": {"$ref": "#/definitions/Account"}},
+				"404": {"schema": {"$ref": "#/definitions/Problem"}}
+			}},
+			"patch": {"responses": {"200": {"schema": {"$ref": "#/definitions/Account"}}}},
+			"delete": {"responses": {"204": {"schema": {"type": "string"}}}}
+		},
+		"/examples/ping": {
+			"get": {"produces": ["text/plain"], "responses": {"200": {"schema": {"type": "string", "example": "pong"}}}}
+		}
+	},
+	"definitions": {
+		"Account": {"type": "object", "properties": {
+			"id": {"type": "integer", "example": 1},
+			"name": {"type": "string", "example": "account name"},
+			"uuid": {"type": "string", "format": "uuid", "example": "550e8400-e29b-41d4-a716-446655440000"},
+			"created_at": {"type": "string", "format": "date-time"},
+			"tags": {"type": "array", "items": {"type": "string", "enum": ["red", "blue"]}}
+		}},
+		"Problem": {"type": "object", "properties": {"status": {"type": "integer"}, "title": {"type": "string"}}}
+	}
+}`
+
+// newTestServer mocks testDoc, keeping resources when stateful.
+func newTestServer(t *testing.T, stateful bool) *httptest.Server {
+	t.Helper()
+	doc, err := spec.Parse([]byte(testDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	e.HTTPErrorHandler = httputil.ErrorHandler
+	s := New(doc)
+	s.Stateful = stateful
+	s.Register(e.Group("/api/v1"))
+	ts := httptest.NewServer(e)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// do sends a request with the X-Mock-Status header status unless empty and
+// returns the status and body of the response.
+func do(t *testing.T, method, url, status, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "" {
+		req.Header.Set(HeaderXMockStatus, status)
+	}
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, strings.TrimSpace(string(b))
+}
+
+type account struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	UUID      string   `json:"uuid"`
+	CreatedAt string   `json:"created_at"`
+	Tags      []string `json:"tags"`
+}
+
+func decode(t *testing.T, body string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		t.Fatalf("%v: %s", err, body)
+	}
+}
+
+func TestExamples(t *testing.T) {
+	ts := newTestServer(t, false)
+
+	status, body := do(t, http.MethodGet, ts.URL+"/api/v1/accounts/7", "", "")
+	var a account
+	decode(t, body, &a)
+	want := account{ID: 1, Name: "account name", UUID: "550e8400-e29b-41d4-a716-446655440000", CreatedAt: "2019-04-19T12:00:00Z", Tags: []string{"red"}}
+	if status != http.StatusOK || a.ID != want.ID || a.Name != want.Name || a.UUID != want.UUID || a.CreatedAt != want.CreatedAt || len(a.Tags) != 1 || a.Tags[0] != "red" {
+		t.Errorf("GET /accounts/7: %d %+v, want 200 %+v", status, a, want)
+	}
+	if status, body := do(t, http.MethodGet, ts.URL+"/api/v1/examples/ping", "", ""); status != http.StatusOK || body != "pong" {
+		t.Errorf("GET /examples/ping: %d %q, want 200 pong", status, body)
+	}
+	// Stateless servers do not keep what is created.
+	do(t, http.MethodPost, ts.URL+"/api/v1/accounts", "", `{"name":"kept"}`)
+	var as []account
+	_, body = do(t, http.MethodGet, ts.URL+"/api/v1/accounts", "", "")
+	decode(t, body, &as)
+	if len(as) != 1 || as[0].Name != "account name" {
+		t.Errorf("GET /accounts: %+v, want the example", as)
+	}
+}
+
+func TestMockStatus(t *testing.T) {
+	ts := newTestServer(t, true)
+	var p httputil.Problem
+
+	status, body := do(t, http.MethodGet, ts.URL+"/api/v1/accounts/1", "404", "")
+	decode(t, body, &p)
+	if status != http.StatusNotFound || p.Status != http.StatusNotFound {
+		t.Errorf("X-Mock-Status 404: %d %s", status, body)
+	}
+	status, body = do(t, http.MethodGet, ts.URL+"/api/v1/accounts/1", "418", "")
+	decode(t, body, &p)
+	if status != http.StatusBadRequest || !strings.Contains(p.Detail, "418 is not declared") {
+		t.Errorf("undeclared X-Mock-Status 418: %d %s", status, body)
+	}
+	if status, _ := do(t, http.MethodDelete, ts.URL+"/api/v1/accounts/1", "204", ""); status != http.StatusNoContent {
+		t.Errorf("X-Mock-Status 204: %d", status)
+	}
+	// A picked status bypasses the simulation, so the account is kept.
+	if status, _ := do(t, http.MethodGet, ts.URL+"/api/v1/accounts/1", "", ""); status != http.StatusOK {
+		t.Errorf("GET /accounts/1 after a mocked delete: %d, want 200", status)
+	}
+}
+
+func TestStateful(t *testing.T) {
+	ts := newTestServer(t, true)
+	api := ts.URL + "/api/v1"
+
+	// Collections start with the example.
+	status, body := do(t, http.MethodGet, api+"/accounts/1", "", "")
+	var seeded account
+	decode(t, body, &seeded)
+	if status != http.StatusOK || seeded.ID != 1 || seeded.Name != "account name" {
+		t.Fatalf("GET /accounts/1: %d %s, want the example", status, body)
+	}
+
+	var created []account
+	for _, name := range []string{"alice", "bob"} {
+		status, body := do(t, http.MethodPost, api+"/accounts", "", `{"id":99,"name":"`+name+`"}`)
+		var a account
+		decode(t, body, &a)
+		if status != http.StatusOK || a.Name != name {
+			t.Fatalf("POST /accounts %s: %d %s", name, status, body)
+		}
+		created = append(created, a)
+	}
+	if created[0].ID != 2 || created[1].ID != 3 {
+		t.Errorf("created ids %d and %d, want 2 and 3", created[0].ID, created[1].ID)
+	}
+	if created[0].UUID == seeded.UUID || created[0].UUID == created[1].UUID {
+		t.Errorf("created uuids %s and %s repeat the example %s", created[0].UUID, created[1].UUID, seeded.UUID)
+	}
+
+	status, body = do(t, http.MethodPatch, api+"/accounts/2", "", `{"name":"carol"}`)
+	var a account
+	decode(t, body, &a)
+	if status != http.StatusOK || a.Name != "carol" || a.UUID != created[0].UUID {
+		t.Errorf("PATCH /accounts/2: %d %s", status, body)
+	}
+	if status, _ := do(t, http.MethodDelete, api+"/accounts/2", "", ""); status != http.StatusNoContent {
+		t.Errorf("DELETE /accounts/2: %d, want 204", status)
+	}
+	if status, _ := do(t, http.MethodGet, api+"/accounts/2", "", ""); status != http.StatusNotFound {
+		t.Errorf("GET /accounts/2 after delete: %d, want 404", status)
+	}
+
+	var as []account
+	_, body = do(t, http.MethodGet, api+"/accounts", "", "")
+	decode(t, body, &as)
+	if len(as) != 2 || as[0].ID != 1 || as[1].ID != 3 || as[1].Name != "bob" {
+		t.Errorf("GET /accounts: %s, want accounts 1 and 3", body)
+	}
+}
@@ -2,6 +2,31 @@ package model
 
 // Admin example
 type Admin struct {
-	ID   int    `json:"id" example:"1"`
-	Name string `json:"name" example:"admin name"`
+	ID     int      `json:"id" example:"1"`
+	Name   string   `json:"name" example:"admin name"`
+	Roles  []string `json:"roles,omitempty" example:"admin"`
+	Scopes []string `json:"scopes,omitempty" example:"read,write"`
+	// KeyID and KeyPrefix name the stored API key the admin authenticated
+	// with, empty for other credentials.
+	KeyID     string `json:"key_id,omitempty" example:"7d444840-9dc0-11d1-b245-5ffdce74fad2" format:"uuid"`
+	KeyPrefix string `json:"key_prefix,omitempty" example:"cel_Xk3b9q"`
+}
+
+// AdminToken example
+type AdminToken struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.sig"`
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.sig"`
+	TokenType    string `json:"token_type" example:"bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"`
+	Admin        Admin  `json:"admin"`
+}
+
+// RefreshToken example
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.sig" validate:"required"`
+}
+
+// RevokeToken example
+type RevokeToken struct {
+	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.sig" validate:"required"`
 }
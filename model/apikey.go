@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Scopes are the scopes that can be granted to OAuth2 clients and API keys.
+var Scopes = []string{"read", "write", "admin"}
+
+// APIKey example
+type APIKey struct {
+	ID      uuid.UUID `json:"id" example:"7d444840-9dc0-11d1-b245-5ffdce74fad2" format:"uuid"`
+	Name    string    `json:"name" example:"ci"`
+	Prefix  string    `json:"prefix" example:"cel_Xk3b9q"`
+	Hash    string    `json:"-"`
+	AdminID int       `json:"admin_id" example:"1"`
+	Scopes  []string  `json:"scopes" example:"read,write"`
+	// Roles are the roles of the admin that created the key, which the key
+	// acts with.
+	Roles      []string   `json:"roles,omitempty" example:"admin"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2030-01-01T00:00:00Z" format:"date-time"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2019-04-19T12:00:00Z" format:"date-time"`
+	CreatedAt  time.Time  `json:"created_at" example:"2019-04-19T12:00:00Z" format:"date-time"`
+}
+
+// Expired reports whether the key is expired at t.
+func (k APIKey) Expired(t time.Time) bool {
+	return k.ExpiresAt != nil && !t.Before(*k.ExpiresAt)
+}
+
+// NewAPIKey example
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"cel_Xk3b9qK1yq2Fq0Wn7Yc6m1bC9fJ4lV8rT2sD5gH0aZ"`
+}
+
+// API key validation errors
+var (
+	ErrExpiresInvalid = badRequest("expires_at must be in the future")
+)
+
+// AddAPIKey example
+type AddAPIKey struct {
+	Name      string     `json:"name" example:"ci" validate:"required,max=64"`
+	Scopes    []string   `json:"scopes" example:"read,write" validate:"required,min=1,oneof=read write admin"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2030-01-01T00:00:00Z" format:"date-time"`
+}
+
+// Validation example
+func (k AddAPIKey) Validation(now time.Time) error {
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return ErrExpiresInvalid
+	}
+	return nil
+}
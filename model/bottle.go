@@ -1,29 +1,93 @@
 package model
 
+import (
+	"errors"
+
+	uuid "github.com/satori/go.uuid"
+)
+
 // Bottle example
 type Bottle struct {
-	ID      int     `json:"id" example:"1"`
-	Name    string  `json:"name" example:"bottle_name"`
-	Account Account `json:"account"`
+	ID   int    `json:"id" example:"1"`
+	Name string `json:"name" example:"bottle_name"`
+	// AccountUUID is the UUID of the owner account, nil for an orphaned bottle.
+	AccountUUID *uuid.UUID `json:"account_uuid,omitempty" example:"6ba7b810-9dad-41d1-80b4-00c04fd430c8" format:"uuid"`
+	Account     *Account   `json:"account,omitempty"`
+}
+
+// AccountID returns the ID of the owner account, or 0 for an orphaned bottle.
+func (b Bottle) AccountID() int {
+	if b.Account == nil {
+		return 0
+	}
+	return b.Account.ID
+}
+
+// Bottle validation errors
+var (
+	ErrBottleNoChange = badRequest("name, account_id or account_uuid is required")
+	ErrBottleNoOwner  = badRequest("account_id or account_uuid is required")
+	// ErrOwnerMismatch is returned when account_id and account_uuid name
+	// different accounts.
+	ErrOwnerMismatch = badRequest("account_id and account_uuid name different accounts")
+)
+
+// AddBottle example
+type AddBottle struct {
+	Name string `json:"name" example:"bottle_name" validate:"required,max=64"`
+	// The owner account is named by AccountID, AccountUUID or both.
+	AccountID   int        `json:"account_id,omitempty" example:"1" format:"int64" validate:"min=1"`
+	AccountUUID *uuid.UUID `json:"account_uuid,omitempty" example:"6ba7b810-9dad-41d1-80b4-00c04fd430c8" format:"uuid"`
 }
 
-// BottlesAll example
-func BottlesAll() ([]Bottle, error) {
-	return bottles, nil
+// Validation example
+func (b AddBottle) Validation() error {
+	if b.AccountID == 0 && b.AccountUUID == nil {
+		return ErrBottleNoOwner
+	}
+	return nil
+}
+
+// AddAccountBottle example
+type AddAccountBottle struct {
+	Name string `json:"name" example:"bottle_name" validate:"required,max=64"`
+}
+
+// UpdateBottle example
+type UpdateBottle struct {
+	Name        string     `json:"name,omitempty" example:"bottle_name" validate:"max=64"`
+	AccountID   int        `json:"account_id,omitempty" example:"1" format:"int64" validate:"min=1"`
+	AccountUUID *uuid.UUID `json:"account_uuid,omitempty" example:"6ba7b810-9dad-41d1-80b4-00c04fd430c8" format:"uuid"`
 }
 
-// BottleOne example
-func BottleOne(id int) (*Bottle, error) {
-	for _, v := range bottles {
-		if id == v.ID {
-			return &v, nil
-		}
+// Validation example
+func (b UpdateBottle) Validation() error {
+	switch {
+	case len(b.Name) == 0 && b.AccountID == 0 && b.AccountUUID == nil:
+		return ErrBottleNoChange
+	default:
+		return nil
 	}
-	return nil, ErrNoRow
 }
 
-var bottles = []Bottle{
-	{ID: 1, Name: "bottle_1", Account: Account{ID: 1, Name: "accout_1"}},
-	{ID: 2, Name: "bottle_2", Account: Account{ID: 2, Name: "accout_2"}},
-	{ID: 3, Name: "bottle_3", Account: Account{ID: 3, Name: "accout_3"}},
+// DeletePolicy decides what happens to the bottles of a deleted account.
+type DeletePolicy string
+
+// Delete policies. The zero value behaves like DeleteRestrict.
+const (
+	// DeleteRestrict refuses to delete an account that owns bottles.
+	DeleteRestrict DeletePolicy = "restrict"
+	// DeleteCascade deletes the bottles together with the account.
+	DeleteCascade DeletePolicy = "cascade"
+	// DeleteOrphan keeps the bottles without an owner.
+	DeleteOrphan DeletePolicy = "orphan"
+)
+
+// ParseDeletePolicy parses restrict, cascade or orphan.
+func ParseDeletePolicy(s string) (DeletePolicy, error) {
+	switch p := DeletePolicy(s); p {
+	case DeleteRestrict, DeleteCascade, DeleteOrphan:
+		return p, nil
+	}
+	return "", errors.New("delete policy must be one of restrict, cascade, orphan")
 }
@@ -1,8 +1,32 @@
 package model
 
-import "errors"
+import "net/http"
 
 var (
 	// ErrNoRow example
-	ErrNoRow = errors.New("no rows in result set")
+	ErrNoRow error = &statusError{"no rows in result set", http.StatusNotFound}
+	// ErrOwnerNotFound example
+	ErrOwnerNotFound = badRequest("owner account is not found")
+	// ErrAccountHasBottles example
+	ErrAccountHasBottles error = &statusError{"account still owns bottles", http.StatusConflict}
 )
+
+// statusError is an error answered with the HTTP status it carries.
+type statusError struct {
+	msg    string
+	status int
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// Status is the HTTP status the error is answered with.
+func (e *statusError) Status() int {
+	return e.status
+}
+
+// badRequest returns an error answered with 400 Bad Request.
+func badRequest(msg string) error {
+	return &statusError{msg, http.StatusBadRequest}
+}
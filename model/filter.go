@@ -0,0 +1,400 @@
+package model
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter is a node of a parsed filter expression such as
+//
+//	name sw "acc" and (id gt 2 or not uuid eq "550e8400-e29b-41d4-a716-446655440000")
+//
+// A nil Filter matches every row.
+type Filter interface {
+	isFilter()
+}
+
+// And matches rows matched by both Left and Right.
+type And struct {
+	Left, Right Filter
+}
+
+// Or matches rows matched by Left or Right.
+type Or struct {
+	Left, Right Filter
+}
+
+// Not matches rows not matched by Filter.
+type Not struct {
+	Filter Filter
+}
+
+// Compare matches rows whose Field compares to Value with Op.
+// Value is an int64 for IntField fields and a string for StringField fields.
+type Compare struct {
+	Field string
+	Op    Op
+	Value interface{}
+}
+
+func (And) isFilter()     {}
+func (Or) isFilter()      {}
+func (Not) isFilter()     {}
+func (Compare) isFilter() {}
+
+// Op is a comparison operator of a filter expression.
+type Op string
+
+// Comparison operators. The I-prefixed string operators ignore case.
+const (
+	OpEq  Op = "eq"
+	OpNe  Op = "ne"
+	OpGt  Op = "gt"
+	OpGe  Op = "ge"
+	OpLt  Op = "lt"
+	OpLe  Op = "le"
+	OpSw  Op = "sw"
+	OpEw  Op = "ew"
+	OpCo  Op = "co"
+	OpIEq Op = "ieq"
+	OpISw Op = "isw"
+	OpIEw Op = "iew"
+	OpICo Op = "ico"
+)
+
+var stringOps = map[Op]bool{OpSw: true, OpEw: true, OpCo: true, OpIEq: true, OpISw: true, OpIEw: true, OpICo: true}
+var orderOps = map[Op]bool{OpEq: true, OpNe: true, OpGt: true, OpGe: true, OpLt: true, OpLe: true}
+
+// FieldKind is the type of a filterable field.
+type FieldKind int
+
+// Kinds of filterable fields.
+const (
+	IntField FieldKind = iota
+	StringField
+)
+
+// AccountFilterFields are the fields an account filter may refer to.
+var AccountFilterFields = map[string]FieldKind{
+	"id":       IntField,
+	"name":     StringField,
+	"uuid":     StringField,
+	"owner_id": IntField,
+}
+
+// BottleFilterFields are the fields a bottle filter may refer to.
+var BottleFilterFields = map[string]FieldKind{
+	"id":         IntField,
+	"name":       StringField,
+	"account_id": IntField,
+}
+
+// FilterError reports a malformed filter expression.
+type FilterError struct {
+	Pos int
+	Msg string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("filter: %s at offset %d", e.Msg, e.Pos)
+}
+
+// Status is the HTTP status a malformed filter is answered with.
+func (e *FilterError) Status() int {
+	return http.StatusBadRequest
+}
+
+// ParseFilter parses a filter expression over the given fields.
+// An empty expression yields a nil Filter.
+//
+//	expr       = term { "or" term }
+//	term       = factor { "and" factor }
+//	factor     = "not" factor | "(" expr ")" | field op value
+//	value      = integer | "quoted string"
+func ParseFilter(s string, fields map[string]FieldKind) (Filter, error) {
+	p := &filterParser{src: s, fields: fields}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, nil
+	}
+	f, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return f, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of filter"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type filterParser struct {
+	src    string
+	off    int
+	tok    token
+	fields map[string]FieldKind
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return &FilterError{Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *filterParser) next() error {
+	for p.off < len(p.src) && (p.src[p.off] == ' ' || p.src[p.off] == '\t') {
+		p.off++
+	}
+	start := p.off
+	if p.off >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+	c := p.src[p.off]
+	switch {
+	case c == '(':
+		p.off++
+		p.tok = token{kind: tokLParen, text: "(", pos: start}
+	case c == ')':
+		p.off++
+		p.tok = token{kind: tokRParen, text: ")", pos: start}
+	case c == '"':
+		var b strings.Builder
+		p.off++
+		for {
+			if p.off >= len(p.src) {
+				return &FilterError{Pos: start, Msg: "unterminated string"}
+			}
+			c := p.src[p.off]
+			p.off++
+			if c == '"' {
+				break
+			}
+			if c == '\\' {
+				if p.off >= len(p.src) {
+					return &FilterError{Pos: start, Msg: "unterminated string"}
+				}
+				c = p.src[p.off]
+				p.off++
+			}
+			b.WriteByte(c)
+		}
+		p.tok = token{kind: tokString, text: b.String(), pos: start}
+	case c == '-' || c >= '0' && c <= '9':
+		p.off++
+		for p.off < len(p.src) && p.src[p.off] >= '0' && p.src[p.off] <= '9' {
+			p.off++
+		}
+		p.tok = token{kind: tokInt, text: p.src[start:p.off], pos: start}
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.off < len(p.src) && (p.src[p.off] == '_' || unicode.IsLetter(rune(p.src[p.off])) || unicode.IsDigit(rune(p.src[p.off]))) {
+			p.off++
+		}
+		p.tok = token{kind: tokIdent, text: p.src[start:p.off], pos: start}
+	default:
+		return &FilterError{Pos: start, Msg: fmt.Sprintf("unexpected character %q", c)}
+	}
+	return nil
+}
+
+func (p *filterParser) keyword(kw string) bool {
+	return p.tok.kind == tokIdent && strings.EqualFold(p.tok.text, kw)
+}
+
+func (p *filterParser) expr() (Filter, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) term() (Filter, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) factor() (Filter, error) {
+	switch {
+	case p.keyword("not"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		f, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Filter: f}, nil
+	case p.tok.kind == tokLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		f, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected \")\", found %s", p.tok)
+		}
+		return f, p.next()
+	}
+	return p.compare()
+}
+
+func (p *filterParser) compare() (Filter, error) {
+	if p.tok.kind != tokIdent {
+		return nil, p.errorf("expected field name, found %s", p.tok)
+	}
+	field := p.tok.text
+	kind, ok := p.fields[field]
+	if !ok {
+		return nil, p.errorf("unknown field %q", field)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokIdent {
+		return nil, p.errorf("expected operator after %q, found %s", field, p.tok)
+	}
+	op := Op(strings.ToLower(p.tok.text))
+	if !orderOps[op] && !stringOps[op] {
+		return nil, p.errorf("unknown operator %q", p.tok.text)
+	}
+	if stringOps[op] && kind != StringField {
+		return nil, p.errorf("operator %q needs a string field, %q is an integer", op, field)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	c := Compare{Field: field, Op: op}
+	switch {
+	case kind == IntField && p.tok.kind == tokInt:
+		n, err := strconv.ParseInt(p.tok.text, 10, 64)
+		if err != nil {
+			return nil, p.errorf("invalid integer %s", p.tok.text)
+		}
+		c.Value = n
+	case kind == StringField && p.tok.kind == tokString:
+		c.Value = p.tok.text
+	case kind == IntField:
+		return nil, p.errorf("field %q needs an integer value, found %s", field, p.tok)
+	default:
+		return nil, p.errorf("field %q needs a quoted string value, found %s", field, p.tok)
+	}
+	return c, p.next()
+}
+
+// Match reports whether the row whose fields are read through value satisfies f.
+func Match(f Filter, value func(field string) interface{}) bool {
+	switch f := f.(type) {
+	case nil:
+		return true
+	case And:
+		return Match(f.Left, value) && Match(f.Right, value)
+	case Or:
+		return Match(f.Left, value) || Match(f.Right, value)
+	case Not:
+		return !Match(f.Filter, value)
+	case Compare:
+		return f.match(value(f.Field))
+	}
+	return false
+}
+
+func (c Compare) match(v interface{}) bool {
+	if stringOps[c.Op] {
+		s, _ := v.(string)
+		want, _ := c.Value.(string)
+		switch c.Op {
+		case OpSw:
+			return strings.HasPrefix(s, want)
+		case OpEw:
+			return strings.HasSuffix(s, want)
+		case OpCo:
+			return strings.Contains(s, want)
+		}
+		s, want = strings.ToLower(s), strings.ToLower(want)
+		switch c.Op {
+		case OpIEq:
+			return s == want
+		case OpISw:
+			return strings.HasPrefix(s, want)
+		case OpIEw:
+			return strings.HasSuffix(s, want)
+		case OpICo:
+			return strings.Contains(s, want)
+		}
+		return false
+	}
+	n, err := compareValues(v, c.Value)
+	if err != nil {
+		return false
+	}
+	switch c.Op {
+	case OpEq:
+		return n == 0
+	case OpNe:
+		return n != 0
+	case OpGt:
+		return n > 0
+	case OpGe:
+		return n >= 0
+	case OpLt:
+		return n < 0
+	case OpLe:
+		return n <= 0
+	}
+	return false
+}
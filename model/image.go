@@ -0,0 +1,29 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Image example
+type Image struct {
+	ID          uuid.UUID `json:"id" example:"7d444840-9dc0-11d1-b245-5ffdce74fad2" format:"uuid"`
+	AccountID   int       `json:"account_id" example:"1" format:"int64"`
+	Filename    string    `json:"filename" example:"avatar.png"`
+	ContentType string    `json:"content_type" example:"image/png"`
+	Size        int64     `json:"size" example:"1024" format:"int64"`
+	SHA256      string    `json:"sha256" example:"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"`
+	CreatedAt   time.Time `json:"created_at" example:"2019-04-19T12:00:00Z" format:"date-time"`
+}
+
+// BlobKey is the key the image content is stored under.
+func (i Image) BlobKey() string {
+	return "accounts/" + strconv.Itoa(i.AccountID) + "/images/" + i.ID.String()
+}
+
+// ThumbnailKey is the key the thumbnail of the named size is stored under.
+func (i Image) ThumbnailKey(size string) string {
+	return i.BlobKey() + "." + size
+}
@@ -0,0 +1,200 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrInvalidCursor is returned when a list cursor cannot be decoded.
+	ErrInvalidCursor = badRequest("cursor is invalid")
+)
+
+// SortKey orders a list by one field.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// ListOptions controls paging and ordering of a list query.
+// A non-empty Cursor takes precedence over Offset. A zero Limit returns every row.
+type ListOptions struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   []SortKey
+}
+
+// Page describes the slice of results a list query returned.
+type Page struct {
+	// Total is the number of matching rows regardless of paging.
+	Total int
+	// Next is the cursor of the following page, empty on the last page.
+	Next string
+}
+
+// ParseSort parses a sort parameter such as "name,-id".
+// Only the given fields are accepted.
+func ParseSort(s string, fields ...string) ([]SortKey, error) {
+	var keys []SortKey
+	if s == "" {
+		return keys, nil
+	}
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		k := SortKey{Field: f}
+		if strings.HasPrefix(f, "-") {
+			k = SortKey{Field: f[1:], Desc: true}
+		}
+		if !contains(fields, k.Field) {
+			return nil, fmt.Errorf("sort field %q is not one of %s", k.Field, strings.Join(fields, ", "))
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sortKeys returns the keys of opts with id appended as a tie breaker,
+// so that every ordering is total and cursors are stable.
+func (opts ListOptions) sortKeys() []SortKey {
+	keys := append([]SortKey{}, opts.Sort...)
+	for _, k := range keys {
+		if k.Field == "id" {
+			return keys
+		}
+	}
+	return append(keys, SortKey{Field: "id"})
+}
+
+// encodeCursor encodes the sort values of the last row of a page.
+func encodeCursor(values []interface{}) string {
+	b, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor decodes a cursor into int64 and string sort values.
+func decodeCursor(cursor string, n int) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	d := json.NewDecoder(strings.NewReader(string(b)))
+	d.UseNumber()
+	var raw []interface{}
+	if err := d.Decode(&raw); err != nil || len(raw) != n {
+		return nil, ErrInvalidCursor
+	}
+	values := make([]interface{}, n)
+	for i, v := range raw {
+		switch v := v.(type) {
+		case json.Number:
+			n, err := v.Int64()
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			values[i] = n
+		case string:
+			values[i] = v
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return values, nil
+}
+
+// compareValues compares two sort values of the same kind.
+func compareValues(a, b interface{}) (int, error) {
+	switch a := a.(type) {
+	case int64:
+		b, ok := b.(int64)
+		if !ok {
+			return 0, ErrInvalidCursor
+		}
+		switch {
+		case a < b:
+			return -1, nil
+		case a > b:
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return 0, ErrInvalidCursor
+		}
+		return strings.Compare(a, b), nil
+	}
+	return 0, ErrInvalidCursor
+}
+
+// paginate orders n rows, whose sort values are read through value, and
+// returns the indexes of the rows on the page selected by opts.
+func paginate(n int, value func(i int, field string) interface{}, opts ListOptions) ([]int, Page, error) {
+	keys := opts.sortKeys()
+	cmp := func(a, b []interface{}) (int, error) {
+		for k, key := range keys {
+			c, err := compareValues(a[k], b[k])
+			if err != nil || c != 0 {
+				if key.Desc {
+					c = -c
+				}
+				return c, err
+			}
+		}
+		return 0, nil
+	}
+	rows := make([][]interface{}, n)
+	idx := make([]int, n)
+	for i := range rows {
+		idx[i] = i
+		rows[i] = make([]interface{}, len(keys))
+		for k, key := range keys {
+			rows[i][k] = value(i, key.Field)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		c, _ := cmp(rows[idx[a]], rows[idx[b]])
+		return c < 0
+	})
+
+	page := Page{Total: n}
+	start := opts.Offset
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor, len(keys))
+		if err != nil {
+			return nil, Page{}, err
+		}
+		start = n
+		for i, j := range idx {
+			c, err := cmp(rows[j], after)
+			if err != nil {
+				return nil, Page{}, err
+			}
+			if c > 0 {
+				start = i
+				break
+			}
+		}
+	}
+	if start > n {
+		start = n
+	}
+	end := n
+	if opts.Limit > 0 && start+opts.Limit < n {
+		end = start + opts.Limit
+		page.Next = encodeCursor(rows[idx[end-1]])
+	}
+	return idx[start:end], page, nil
+}
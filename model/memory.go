@@ -0,0 +1,442 @@
+package model
+
+import (
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// MemoryStore keeps accounts and bottles in process memory.
+// It is safe for concurrent use.
+type MemoryStore struct {
+	// DeletePolicy decides what happens to the bottles of a deleted account.
+	DeletePolicy DeletePolicy
+
+	mu           sync.RWMutex
+	accountMaxID int
+	accounts     []Account
+	bottleMaxID  int
+	bottles      []bottleRow
+	images       []Image
+	apiKeys      []APIKey
+}
+
+// bottleRow is a stored bottle; its owner is resolved when it is read.
+type bottleRow struct {
+	ID        int
+	Name      string
+	AccountID int
+}
+
+// NewMemoryStore returns a MemoryStore seeded with example data.
+func NewMemoryStore() *MemoryStore {
+	u1 := uuid.FromStringOrNil("6ba7b810-9dad-41d1-80b4-00c04fd430c8")
+	u2 := uuid.FromStringOrNil("6ba7b811-9dad-41d1-80b4-00c04fd430c8")
+	u3 := uuid.FromStringOrNil("6ba7b812-9dad-41d1-80b4-00c04fd430c8")
+	return &MemoryStore{
+		accountMaxID: 3,
+		accounts: []Account{
+			{ID: 1, Name: "account_1", UUID: u1},
+			{ID: 2, Name: "account_2", UUID: u2},
+			{ID: 3, Name: "account_3", UUID: u3},
+		},
+		bottleMaxID: 3,
+		bottles: []bottleRow{
+			{ID: 1, Name: "bottle_1", AccountID: 1},
+			{ID: 2, Name: "bottle_2", AccountID: 2},
+			{ID: 3, Name: "bottle_3", AccountID: 3},
+		},
+	}
+}
+
+// AccountsAll example
+func (s *MemoryStore) AccountsAll(filter Filter, opts ListOptions) ([]Account, Page, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	as := []Account{}
+	for k, v := range s.accounts {
+		if Match(filter, func(field string) interface{} { return accountValue(v, field) }) {
+			as = append(as, s.accounts[k])
+		}
+	}
+	idx, page, err := paginate(len(as), func(i int, field string) interface{} {
+		return accountValue(as[i], field)
+	}, opts)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	res := make([]Account, len(idx))
+	for i, j := range idx {
+		res[i] = as[j]
+	}
+	return res, page, nil
+}
+
+// AccountOne example
+func (s *MemoryStore) AccountOne(id int) (Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.accounts {
+		if id == v.ID {
+			return v, nil
+		}
+	}
+	return Account{}, ErrNoRow
+}
+
+// AccountByUUID example
+func (s *MemoryStore) AccountByUUID(u uuid.UUID) (Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.accounts {
+		if uuid.Equal(u, v.UUID) {
+			return v, nil
+		}
+	}
+	return Account{}, ErrNoRow
+}
+
+// Insert example
+func (s *MemoryStore) Insert(a Account) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accountMaxID++
+	a.ID = s.accountMaxID
+	a.UUID = uuid.Must(uuid.NewV4())
+	s.accounts = append(s.accounts, a)
+	return s.accountMaxID, nil
+}
+
+// Delete example
+func (s *MemoryStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range s.accounts {
+		if id == v.ID {
+			if err := s.applyDeletePolicy(id); err != nil {
+				return err
+			}
+			s.accounts = append(s.accounts[:k], s.accounts[k+1:]...)
+			s.deleteImages(id)
+			return nil
+		}
+	}
+	return ErrNoRow
+}
+
+func (s *MemoryStore) applyDeletePolicy(accountID int) error {
+	bs := s.bottles[:0:0]
+	for _, b := range s.bottles {
+		if b.AccountID != accountID {
+			bs = append(bs, b)
+			continue
+		}
+		switch s.DeletePolicy {
+		case DeleteCascade:
+			// dropped with the account
+		case DeleteOrphan:
+			b.AccountID = 0
+			bs = append(bs, b)
+		default:
+			return ErrAccountHasBottles
+		}
+	}
+	s.bottles = bs
+	return nil
+}
+
+// Update example
+func (s *MemoryStore) Update(a Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range s.accounts {
+		if a.ID == v.ID {
+			s.accounts[k].Name = a.Name
+			return nil
+		}
+	}
+	return ErrNoRow
+}
+
+// BottlesAll example
+func (s *MemoryStore) BottlesAll(filter Filter, opts ListOptions) ([]Bottle, Page, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	bs := []Bottle{}
+	for _, v := range s.bottles {
+		b := s.bottle(v)
+		if Match(filter, func(field string) interface{} { return bottleValue(b, field) }) {
+			bs = append(bs, b)
+		}
+	}
+	idx, page, err := paginate(len(bs), func(i int, field string) interface{} {
+		return bottleValue(bs[i], field)
+	}, opts)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	res := make([]Bottle, len(idx))
+	for i, j := range idx {
+		res[i] = bs[j]
+	}
+	return res, page, nil
+}
+
+// BottleOne example
+func (s *MemoryStore) BottleOne(id int) (*Bottle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.bottles {
+		if id == v.ID {
+			b := s.bottle(v)
+			return &b, nil
+		}
+	}
+	return nil, ErrNoRow
+}
+
+// InsertBottle example
+func (s *MemoryStore) InsertBottle(b Bottle) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.hasAccount(b.AccountID()) {
+		return 0, ErrOwnerNotFound
+	}
+	s.bottleMaxID++
+	s.bottles = append(s.bottles, bottleRow{ID: s.bottleMaxID, Name: b.Name, AccountID: b.AccountID()})
+	return s.bottleMaxID, nil
+}
+
+// UpdateBottle example
+func (s *MemoryStore) UpdateBottle(b Bottle) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// An orphaned bottle stays orphaned unless it is given an owner.
+	if b.AccountID() != 0 && !s.hasAccount(b.AccountID()) {
+		return ErrOwnerNotFound
+	}
+	for k, v := range s.bottles {
+		if b.ID == v.ID {
+			s.bottles[k].Name = b.Name
+			s.bottles[k].AccountID = b.AccountID()
+			return nil
+		}
+	}
+	return ErrNoRow
+}
+
+// DeleteBottle example
+func (s *MemoryStore) DeleteBottle(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range s.bottles {
+		if id == v.ID {
+			s.bottles = append(s.bottles[:k], s.bottles[k+1:]...)
+			return nil
+		}
+	}
+	return ErrNoRow
+}
+
+// bottle resolves the owner of a stored bottle. The caller must hold s.mu.
+func (s *MemoryStore) bottle(r bottleRow) Bottle {
+	b := Bottle{ID: r.ID, Name: r.Name}
+	for _, a := range s.accounts {
+		if a.ID == r.AccountID {
+			a := a
+			b.AccountUUID = &a.UUID
+			b.Account = &a
+			break
+		}
+	}
+	return b
+}
+
+func (s *MemoryStore) hasAccount(id int) bool {
+	for _, a := range s.accounts {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ImagesAll example
+func (s *MemoryStore) ImagesAll(accountID int) ([]Image, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	is := []Image{}
+	for _, v := range s.images {
+		if v.AccountID == accountID {
+			is = append(is, v)
+		}
+	}
+	return is, nil
+}
+
+// ImageOne example
+func (s *MemoryStore) ImageOne(accountID int, id uuid.UUID) (Image, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.images {
+		if v.AccountID == accountID && uuid.Equal(v.ID, id) {
+			return v, nil
+		}
+	}
+	return Image{}, ErrNoRow
+}
+
+// InsertImage example
+func (s *MemoryStore) InsertImage(img Image) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.hasAccount(img.AccountID) {
+		return ErrOwnerNotFound
+	}
+	s.images = append(s.images, img)
+	return nil
+}
+
+// DeleteImage example
+func (s *MemoryStore) DeleteImage(accountID int, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range s.images {
+		if v.AccountID == accountID && uuid.Equal(v.ID, id) {
+			s.images = append(s.images[:k], s.images[k+1:]...)
+			return nil
+		}
+	}
+	return ErrNoRow
+}
+
+// deleteImages drops the image metadata of a deleted account. The caller must hold s.mu.
+func (s *MemoryStore) deleteImages(accountID int) {
+	is := s.images[:0:0]
+	for _, v := range s.images {
+		if v.AccountID != accountID {
+			is = append(is, v)
+		}
+	}
+	s.images = is
+}
+
+// APIKeysAll example
+func (s *MemoryStore) APIKeysAll() ([]APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ks := make([]APIKey, len(s.apiKeys))
+	for k, v := range s.apiKeys {
+		ks[k] = copyAPIKey(v)
+	}
+	return ks, nil
+}
+
+// APIKeyOne example
+func (s *MemoryStore) APIKeyOne(id uuid.UUID) (APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.apiKeys {
+		if uuid.Equal(v.ID, id) {
+			return copyAPIKey(v), nil
+		}
+	}
+	return APIKey{}, ErrNoRow
+}
+
+// APIKeyByHash example
+func (s *MemoryStore) APIKeyByHash(hash string) (APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.apiKeys {
+		if v.Hash == hash {
+			return copyAPIKey(v), nil
+		}
+	}
+	return APIKey{}, ErrNoRow
+}
+
+// InsertAPIKey example
+func (s *MemoryStore) InsertAPIKey(k APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKeys = append(s.apiKeys, copyAPIKey(k))
+	return nil
+}
+
+// UpdateAPIKey example
+func (s *MemoryStore) UpdateAPIKey(k APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, v := range s.apiKeys {
+		if uuid.Equal(v.ID, k.ID) {
+			s.apiKeys[i] = copyAPIKey(k)
+			return nil
+		}
+	}
+	return ErrNoRow
+}
+
+// TouchAPIKey example
+func (s *MemoryStore) TouchAPIKey(id uuid.UUID, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, v := range s.apiKeys {
+		if uuid.Equal(v.ID, id) {
+			s.apiKeys[i].LastUsedAt = &t
+			return nil
+		}
+	}
+	return ErrNoRow
+}
+
+// DeleteAPIKey example
+func (s *MemoryStore) DeleteAPIKey(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, v := range s.apiKeys {
+		if uuid.Equal(v.ID, id) {
+			s.apiKeys = append(s.apiKeys[:i], s.apiKeys[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNoRow
+}
+
+// copyAPIKey copies k so that callers cannot change a stored key through its slices or pointers.
+func copyAPIKey(k APIKey) APIKey {
+	k.Scopes = append([]string{}, k.Scopes...)
+	k.Roles = append([]string{}, k.Roles...)
+	if k.ExpiresAt != nil {
+		t := *k.ExpiresAt
+		k.ExpiresAt = &t
+	}
+	if k.LastUsedAt != nil {
+		t := *k.LastUsedAt
+		k.LastUsedAt = &t
+	}
+	return k
+}
+
+func accountValue(a Account, field string) interface{} {
+	switch field {
+	case "name":
+		return a.Name
+	case "uuid":
+		return a.UUID.String()
+	case "owner_id":
+		return int64(a.OwnerID)
+	}
+	return int64(a.ID)
+}
+
+func bottleValue(b Bottle, field string) interface{} {
+	switch field {
+	case "name":
+		return b.Name
+	case "account_id":
+		return int64(b.AccountID())
+	}
+	return int64(b.ID)
+}
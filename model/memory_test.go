@@ -0,0 +1,103 @@
+package model
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMemoryStoreConcurrentAccounts(t *testing.T) {
+	s := NewMemoryStore()
+	const workers = 16
+	const perWorker = 50
+
+	var wg sync.WaitGroup
+	ids := make(chan int, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				id, err := s.Insert(Account{Name: "concurrent"})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if err := s.Update(Account{ID: id, Name: "renamed"}); err != nil {
+					t.Error(err)
+				}
+				if _, err := s.AccountOne(id); err != nil {
+					t.Error(err)
+				}
+				if _, _, err := s.AccountsAll(nil, ListOptions{}); err != nil {
+					t.Error(err)
+				}
+				ids <- id
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := map[int]bool{}
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("id %d was handed out twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Fatalf("got %d ids, want %d", len(seen), workers*perWorker)
+	}
+
+	for id := range seen {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if err := s.Delete(id); err != nil {
+				t.Errorf("delete %d: %v", id, err)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	as, page, err := s.AccountsAll(nil, ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 3 || page.Total != 3 {
+		t.Fatalf("got %d accounts (total %d) after deleting, want the 3 seeded ones", len(as), page.Total)
+	}
+}
+
+func TestMemoryStoreConcurrentBottles(t *testing.T) {
+	s := NewMemoryStore()
+	var wg sync.WaitGroup
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			owner := &Account{ID: w%3 + 1}
+			for i := 0; i < 50; i++ {
+				id, err := s.InsertBottle(Bottle{Name: "bottle", Account: owner})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if err := s.UpdateBottle(Bottle{ID: id, Name: "renamed", Account: owner}); err != nil {
+					t.Error(err)
+				}
+				if err := s.DeleteBottle(id); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	bs, _, err := s.BottlesAll(nil, ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 3 {
+		t.Fatalf("got %d bottles, want the 3 seeded ones", len(bs))
+	}
+}
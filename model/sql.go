@@ -0,0 +1,716 @@
+package model
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"modernc.org/sqlite"
+)
+
+func init() {
+	// The lower function of SQLite only folds ASCII letters; go_lower folds
+	// like strings.ToLower so that the case-insensitive filters of SQLStore
+	// match the same rows as those of MemoryStore.
+	sqlite.MustRegisterDeterministicScalarFunction("go_lower", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		if s, ok := args[0].(string); ok {
+			return strings.ToLower(s), nil
+		}
+		return args[0], nil
+	})
+}
+
+// migrations are applied in order; the index+1 is the schema version.
+var migrations = []string{
+	`CREATE TABLE accounts (
+		id   INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL
+	);
+	CREATE TABLE bottles (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		name       TEXT NOT NULL,
+		account_id INTEGER NOT NULL REFERENCES accounts(id)
+	);
+	CREATE INDEX bottles_account_id ON bottles(account_id);`,
+	`INSERT INTO accounts (id, name) VALUES (1, 'account_1'), (2, 'account_2'), (3, 'account_3');
+	INSERT INTO bottles (id, name, account_id) VALUES (1, 'bottle_1', 1), (2, 'bottle_2', 2), (3, 'bottle_3', 3);`,
+	// backfill the seed accounts with the UUIDs of MemoryStore and the
+	// others with random version 4 UUIDs
+	`ALTER TABLE accounts ADD COLUMN uuid TEXT;
+	UPDATE accounts SET uuid = CASE
+		WHEN id = 1 AND name = 'account_1' THEN '6ba7b810-9dad-41d1-80b4-00c04fd430c8'
+		WHEN id = 2 AND name = 'account_2' THEN '6ba7b811-9dad-41d1-80b4-00c04fd430c8'
+		WHEN id = 3 AND name = 'account_3' THEN '6ba7b812-9dad-41d1-80b4-00c04fd430c8'
+		ELSE lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' ||
+			substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) ||
+			substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))
+		END;
+	CREATE UNIQUE INDEX accounts_uuid ON accounts(uuid);`,
+	// allow orphaned bottles
+	`CREATE TABLE bottles_new (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		name       TEXT NOT NULL,
+		account_id INTEGER REFERENCES accounts(id)
+	);
+	INSERT INTO bottles_new (id, name, account_id) SELECT id, name, account_id FROM bottles;
+	DROP TABLE bottles;
+	ALTER TABLE bottles_new RENAME TO bottles;
+	CREATE INDEX bottles_account_id ON bottles(account_id);`,
+	`CREATE TABLE images (
+		id           TEXT PRIMARY KEY,
+		account_id   INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		filename     TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size         INTEGER NOT NULL,
+		sha256       TEXT NOT NULL,
+		created_at   TIMESTAMP NOT NULL
+	);
+	CREATE INDEX images_account_id ON images(account_id);`,
+	`CREATE TABLE api_keys (
+		id           TEXT PRIMARY KEY,
+		name         TEXT NOT NULL,
+		prefix       TEXT NOT NULL,
+		hash         TEXT NOT NULL UNIQUE,
+		admin_id     INTEGER NOT NULL,
+		scopes       TEXT NOT NULL,
+		expires_at   TIMESTAMP,
+		last_used_at TIMESTAMP,
+		created_at   TIMESTAMP NOT NULL
+	);`,
+	`ALTER TABLE accounts ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE api_keys ADD COLUMN roles TEXT NOT NULL DEFAULT '';`,
+}
+
+// SQLStore keeps accounts and bottles in a SQLite database.
+type SQLStore struct {
+	// DeletePolicy decides what happens to the bottles of a deleted account.
+	DeletePolicy DeletePolicy
+
+	db *sql.DB
+}
+
+// OpenSQLStore opens the SQLite database at dsn and migrates it to the latest schema.
+func OpenSQLStore(dsn string) (*SQLStore, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite enforces foreign keys per connection, so keep a single one.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
+		db.Close()
+		return nil, err
+	}
+	s := &SQLStore{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the underlying database.
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLStore) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return err
+	}
+	var version int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return err
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %v", i+1, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, i+1); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AccountsAll example
+func (s *SQLStore) AccountsAll(filter Filter, opts ListOptions) ([]Account, Page, error) {
+	var where []string
+	var args []interface{}
+	if filter != nil {
+		cond, condArgs, err := filterClause(filter, accountColumns)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+	var page Page
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM accounts`+whereClause(where), args...).Scan(&page.Total); err != nil {
+		return nil, Page{}, err
+	}
+	query, args, err := listQuery(`SELECT id, name, uuid, owner_id FROM accounts`, where, args, accountColumns, opts)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+	as := []Account{}
+	for rows.Next() {
+		var a Account
+		if err := rows.Scan(&a.ID, &a.Name, &a.UUID, &a.OwnerID); err != nil {
+			return nil, Page{}, err
+		}
+		as = append(as, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+	if opts.Limit > 0 && len(as) > opts.Limit {
+		as = as[:opts.Limit]
+		page.Next = encodeCursor(cursorValues(opts, func(field string) interface{} {
+			return accountValue(as[len(as)-1], field)
+		}))
+	}
+	return as, page, nil
+}
+
+// AccountOne example
+func (s *SQLStore) AccountOne(id int) (Account, error) {
+	var a Account
+	err := s.db.QueryRow(`SELECT id, name, uuid, owner_id FROM accounts WHERE id = ?`, id).Scan(&a.ID, &a.Name, &a.UUID, &a.OwnerID)
+	if err == sql.ErrNoRows {
+		return Account{}, ErrNoRow
+	}
+	return a, err
+}
+
+// AccountByUUID example
+func (s *SQLStore) AccountByUUID(u uuid.UUID) (Account, error) {
+	var a Account
+	err := s.db.QueryRow(`SELECT id, name, uuid, owner_id FROM accounts WHERE uuid = ?`, u.String()).Scan(&a.ID, &a.Name, &a.UUID, &a.OwnerID)
+	if err == sql.ErrNoRows {
+		return Account{}, ErrNoRow
+	}
+	return a, err
+}
+
+// Insert example
+func (s *SQLStore) Insert(a Account) (int, error) {
+	res, err := s.db.Exec(`INSERT INTO accounts (name, uuid, owner_id) VALUES (?, ?, ?)`, a.Name, uuid.Must(uuid.NewV4()).String(), a.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// Delete example
+func (s *SQLStore) Delete(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var bottles int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM bottles WHERE account_id = ?`, id).Scan(&bottles); err != nil {
+		return err
+	}
+	if bottles > 0 {
+		switch s.DeletePolicy {
+		case DeleteCascade:
+			_, err = tx.Exec(`DELETE FROM bottles WHERE account_id = ?`, id)
+		case DeleteOrphan:
+			_, err = tx.Exec(`UPDATE bottles SET account_id = NULL WHERE account_id = ?`, id)
+		default:
+			err = ErrAccountHasBottles
+		}
+		if err != nil {
+			return err
+		}
+	}
+	res, err := tx.Exec(`DELETE FROM accounts WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNoRow
+	}
+	return tx.Commit()
+}
+
+// Update example
+func (s *SQLStore) Update(a Account) error {
+	res, err := s.db.Exec(`UPDATE accounts SET name = ? WHERE id = ?`, a.Name, a.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNoRow
+	}
+	return nil
+}
+
+const bottleSelect = `SELECT b.id, b.name, a.id, a.name, a.uuid, a.owner_id FROM bottles b LEFT JOIN accounts a ON a.id = b.account_id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBottle(r rowScanner) (Bottle, error) {
+	var b Bottle
+	var id sql.NullInt64
+	var name sql.NullString
+	var u uuid.NullUUID
+	var owner sql.NullInt64
+	if err := r.Scan(&b.ID, &b.Name, &id, &name, &u, &owner); err != nil {
+		return Bottle{}, err
+	}
+	if id.Valid {
+		b.Account = &Account{ID: int(id.Int64), Name: name.String, UUID: u.UUID, OwnerID: int(owner.Int64)}
+		b.AccountUUID = &b.Account.UUID
+	}
+	return b, nil
+}
+
+// BottlesAll example
+func (s *SQLStore) BottlesAll(filter Filter, opts ListOptions) ([]Bottle, Page, error) {
+	var where []string
+	var args []interface{}
+	if filter != nil {
+		cond, condArgs, err := filterClause(filter, bottleColumns)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+	var page Page
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM bottles b`+whereClause(where), args...).Scan(&page.Total); err != nil {
+		return nil, Page{}, err
+	}
+	query, args, err := listQuery(bottleSelect, where, args, bottleColumns, opts)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+	bs := []Bottle{}
+	for rows.Next() {
+		b, err := scanBottle(rows)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		bs = append(bs, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+	if opts.Limit > 0 && len(bs) > opts.Limit {
+		bs = bs[:opts.Limit]
+		page.Next = encodeCursor(cursorValues(opts, func(field string) interface{} {
+			return bottleValue(bs[len(bs)-1], field)
+		}))
+	}
+	return bs, page, nil
+}
+
+// BottleOne example
+func (s *SQLStore) BottleOne(id int) (*Bottle, error) {
+	b, err := scanBottle(s.db.QueryRow(bottleSelect+` WHERE b.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNoRow
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// InsertBottle example
+func (s *SQLStore) InsertBottle(b Bottle) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if err := ownerExists(tx, b.AccountID()); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`INSERT INTO bottles (name, account_id) VALUES (?, ?)`, b.Name, b.AccountID())
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), tx.Commit()
+}
+
+// UpdateBottle example
+func (s *SQLStore) UpdateBottle(b Bottle) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// An orphaned bottle stays orphaned unless it is given an owner.
+	var accountID interface{}
+	if b.AccountID() != 0 {
+		if err := ownerExists(tx, b.AccountID()); err != nil {
+			return err
+		}
+		accountID = b.AccountID()
+	}
+	res, err := tx.Exec(`UPDATE bottles SET name = ?, account_id = ? WHERE id = ?`, b.Name, accountID, b.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNoRow
+	}
+	return tx.Commit()
+}
+
+// DeleteBottle example
+func (s *SQLStore) DeleteBottle(id int) error {
+	res, err := s.db.Exec(`DELETE FROM bottles WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNoRow
+	}
+	return nil
+}
+
+func ownerExists(tx *sql.Tx, accountID int) error {
+	var n int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM accounts WHERE id = ?`, accountID).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrOwnerNotFound
+	}
+	return nil
+}
+
+const imageSelect = `SELECT id, account_id, filename, content_type, size, sha256, created_at FROM images`
+
+func scanImage(r rowScanner) (Image, error) {
+	var i Image
+	err := r.Scan(&i.ID, &i.AccountID, &i.Filename, &i.ContentType, &i.Size, &i.SHA256, &i.CreatedAt)
+	return i, err
+}
+
+// ImagesAll example
+func (s *SQLStore) ImagesAll(accountID int) ([]Image, error) {
+	rows, err := s.db.Query(imageSelect+` WHERE account_id = ? ORDER BY created_at, id`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	is := []Image{}
+	for rows.Next() {
+		i, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		is = append(is, i)
+	}
+	return is, rows.Err()
+}
+
+// ImageOne example
+func (s *SQLStore) ImageOne(accountID int, id uuid.UUID) (Image, error) {
+	i, err := scanImage(s.db.QueryRow(imageSelect+` WHERE account_id = ? AND id = ?`, accountID, id.String()))
+	if err == sql.ErrNoRows {
+		return Image{}, ErrNoRow
+	}
+	return i, err
+}
+
+// InsertImage example
+func (s *SQLStore) InsertImage(img Image) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := ownerExists(tx, img.AccountID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO images (id, account_id, filename, content_type, size, sha256, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		img.ID.String(), img.AccountID, img.Filename, img.ContentType, img.Size, img.SHA256, img.CreatedAt.UTC())
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteImage example
+func (s *SQLStore) DeleteImage(accountID int, id uuid.UUID) error {
+	res, err := s.db.Exec(`DELETE FROM images WHERE account_id = ? AND id = ?`, accountID, id.String())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNoRow
+	}
+	return nil
+}
+
+const apiKeySelect = `SELECT id, name, prefix, hash, admin_id, scopes, roles, expires_at, last_used_at, created_at FROM api_keys`
+
+func scanAPIKey(r rowScanner) (APIKey, error) {
+	var k APIKey
+	var scopes, roles string
+	err := r.Scan(&k.ID, &k.Name, &k.Prefix, &k.Hash, &k.AdminID, &scopes, &roles, &k.ExpiresAt, &k.LastUsedAt, &k.CreatedAt)
+	k.Scopes = strings.Fields(scopes)
+	k.Roles = strings.Fields(roles)
+	return k, err
+}
+
+// utcOrNil converts t to UTC, keeping nil as NULL.
+func utcOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// APIKeysAll example
+func (s *SQLStore) APIKeysAll() ([]APIKey, error) {
+	rows, err := s.db.Query(apiKeySelect + ` ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ks := []APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		ks = append(ks, k)
+	}
+	return ks, rows.Err()
+}
+
+// APIKeyOne example
+func (s *SQLStore) APIKeyOne(id uuid.UUID) (APIKey, error) {
+	k, err := scanAPIKey(s.db.QueryRow(apiKeySelect+` WHERE id = ?`, id.String()))
+	if err == sql.ErrNoRows {
+		return APIKey{}, ErrNoRow
+	}
+	return k, err
+}
+
+// APIKeyByHash example
+func (s *SQLStore) APIKeyByHash(hash string) (APIKey, error) {
+	k, err := scanAPIKey(s.db.QueryRow(apiKeySelect+` WHERE hash = ?`, hash))
+	if err == sql.ErrNoRows {
+		return APIKey{}, ErrNoRow
+	}
+	return k, err
+}
+
+// InsertAPIKey example
+func (s *SQLStore) InsertAPIKey(k APIKey) error {
+	_, err := s.db.Exec(`INSERT INTO api_keys (id, name, prefix, hash, admin_id, scopes, roles, expires_at, last_used_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		k.ID.String(), k.Name, k.Prefix, k.Hash, k.AdminID, strings.Join(k.Scopes, " "), strings.Join(k.Roles, " "), utcOrNil(k.ExpiresAt), utcOrNil(k.LastUsedAt), k.CreatedAt.UTC())
+	return err
+}
+
+// UpdateAPIKey example
+func (s *SQLStore) UpdateAPIKey(k APIKey) error {
+	res, err := s.db.Exec(`UPDATE api_keys SET name = ?, prefix = ?, hash = ?, admin_id = ?, scopes = ?, roles = ?, expires_at = ?, last_used_at = ? WHERE id = ?`,
+		k.Name, k.Prefix, k.Hash, k.AdminID, strings.Join(k.Scopes, " "), strings.Join(k.Roles, " "), utcOrNil(k.ExpiresAt), utcOrNil(k.LastUsedAt), k.ID.String())
+	return affectedOne(res, err)
+}
+
+// TouchAPIKey example
+func (s *SQLStore) TouchAPIKey(id uuid.UUID, t time.Time) error {
+	res, err := s.db.Exec(`UPDATE api_keys SET last_used_at = ? WHERE id = ?`, t.UTC(), id.String())
+	return affectedOne(res, err)
+}
+
+// DeleteAPIKey example
+func (s *SQLStore) DeleteAPIKey(id uuid.UUID) error {
+	res, err := s.db.Exec(`DELETE FROM api_keys WHERE id = ?`, id.String())
+	return affectedOne(res, err)
+}
+
+// affectedOne turns the result of a statement that should change one row into ErrNoRow when it changed none.
+func affectedOne(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNoRow
+	}
+	return nil
+}
+
+// Columns backing the sort fields of each list. Orphaned bottles count as
+// account 0, as in MemoryStore.
+var (
+	accountColumns = map[string]string{"id": "id", "name": "name", "uuid": "uuid", "owner_id": "owner_id"}
+	bottleColumns  = map[string]string{"id": "b.id", "name": "b.name", "account_id": "COALESCE(b.account_id, 0)"}
+)
+
+var sqlOps = map[Op]string{OpEq: "=", OpNe: "<>", OpGt: ">", OpGe: ">=", OpLt: "<", OpLe: "<="}
+
+// filterClause translates f into a SQL condition over columns.
+func filterClause(f Filter, columns map[string]string) (string, []interface{}, error) {
+	switch f := f.(type) {
+	case And:
+		return joinClause(f.Left, f.Right, ` AND `, columns)
+	case Or:
+		return joinClause(f.Left, f.Right, ` OR `, columns)
+	case Not:
+		c, args, err := filterClause(f.Filter, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		return `NOT ` + c, args, nil
+	case Compare:
+		col, ok := columns[f.Field]
+		if !ok {
+			return "", nil, &FilterError{Msg: fmt.Sprintf("field %q is not supported by this store", f.Field)}
+		}
+		if op, ok := sqlOps[f.Op]; ok {
+			return `(` + col + ` ` + op + ` ?)`, []interface{}{f.Value}, nil
+		}
+		switch f.Op {
+		case OpIEq, OpISw, OpIEw, OpICo:
+			col = `go_lower(` + col + `)`
+			f.Value = strings.ToLower(f.Value.(string))
+		}
+		// instr and substr compare bytes, unlike LIKE which ignores ASCII case.
+		switch f.Op {
+		case OpIEq:
+			return `(` + col + ` = ?)`, []interface{}{f.Value}, nil
+		case OpSw, OpISw:
+			return `(instr(` + col + `, ?) = 1 OR ? = '')`, []interface{}{f.Value, f.Value}, nil
+		case OpEw, OpIEw:
+			return `(substr(` + col + `, -length(?)) = ? OR ? = '')`, []interface{}{f.Value, f.Value, f.Value}, nil
+		case OpCo, OpICo:
+			return `(instr(` + col + `, ?) > 0 OR ? = '')`, []interface{}{f.Value, f.Value}, nil
+		}
+		return "", nil, &FilterError{Msg: fmt.Sprintf("operator %q is not supported", f.Op)}
+	}
+	return "", nil, fmt.Errorf("unexpected filter %T", f)
+}
+
+func joinClause(left, right Filter, op string, columns map[string]string) (string, []interface{}, error) {
+	l, largs, err := filterClause(left, columns)
+	if err != nil {
+		return "", nil, err
+	}
+	r, rargs, err := filterClause(right, columns)
+	if err != nil {
+		return "", nil, err
+	}
+	return `(` + l + op + r + `)`, append(largs, rargs...), nil
+}
+
+func whereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+	return ` WHERE ` + strings.Join(where, ` AND `)
+}
+
+func cursorValues(opts ListOptions, value func(field string) interface{}) []interface{} {
+	keys := opts.sortKeys()
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		values[i] = value(k.Field)
+	}
+	return values
+}
+
+// listQuery completes query with the keyset condition, ordering and paging
+// described by opts. One row more than opts.Limit is requested so that the
+// caller can tell whether a next page exists.
+func listQuery(query string, where []string, args []interface{}, columns map[string]string, opts ListOptions) (string, []interface{}, error) {
+	where = append([]string{}, where...)
+	args = append([]interface{}{}, args...)
+	keys := opts.sortKeys()
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor, len(keys))
+		if err != nil {
+			return "", nil, err
+		}
+		var ors []string
+		for i, k := range keys {
+			var ands []string
+			for j := 0; j < i; j++ {
+				ands = append(ands, columns[keys[j].Field]+` = ?`)
+				args = append(args, after[j])
+			}
+			op := ` > ?`
+			if k.Desc {
+				op = ` < ?`
+			}
+			ands = append(ands, columns[k.Field]+op)
+			args = append(args, after[i])
+			ors = append(ors, `(`+strings.Join(ands, ` AND `)+`)`)
+		}
+		where = append(where, `(`+strings.Join(ors, ` OR `)+`)`)
+	}
+	query += whereClause(where)
+
+	order := make([]string, len(keys))
+	for i, k := range keys {
+		order[i] = columns[k.Field]
+		if k.Desc {
+			order[i] += ` DESC`
+		}
+	}
+	query += ` ORDER BY ` + strings.Join(order, `, `)
+
+	limit := -1
+	if opts.Limit > 0 {
+		limit = opts.Limit + 1
+	}
+	query += ` LIMIT ?`
+	args = append(args, limit)
+	if opts.Cursor == "" && opts.Offset > 0 {
+		query += ` OFFSET ?`
+		args = append(args, opts.Offset)
+	}
+	return query, args, nil
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Fields a list of accounts or bottles can be sorted by.
+var (
+	AccountSortFields = []string{"id", "name"}
+	BottleSortFields  = []string{"id", "name"}
+)
+
+// AccountStore is the persistence backend for accounts.
+type AccountStore interface {
+	AccountsAll(filter Filter, opts ListOptions) ([]Account, Page, error)
+	AccountOne(id int) (Account, error)
+	AccountByUUID(u uuid.UUID) (Account, error)
+	Insert(a Account) (int, error)
+	Update(a Account) error
+	Delete(id int) error
+}
+
+// BottleStore is the persistence backend for bottles.
+type BottleStore interface {
+	BottlesAll(filter Filter, opts ListOptions) ([]Bottle, Page, error)
+	BottleOne(id int) (*Bottle, error)
+	InsertBottle(b Bottle) (int, error)
+	UpdateBottle(b Bottle) error
+	DeleteBottle(id int) error
+}
+
+// ImageStore is the persistence backend for account image metadata.
+type ImageStore interface {
+	ImagesAll(accountID int) ([]Image, error)
+	ImageOne(accountID int, id uuid.UUID) (Image, error)
+	InsertImage(img Image) error
+	DeleteImage(accountID int, id uuid.UUID) error
+}
+
+// APIKeyStore is the persistence backend for API keys. Keys are looked up
+// by the hash of their secret, which is never stored.
+type APIKeyStore interface {
+	APIKeysAll() ([]APIKey, error)
+	APIKeyOne(id uuid.UUID) (APIKey, error)
+	APIKeyByHash(hash string) (APIKey, error)
+	InsertAPIKey(k APIKey) error
+	UpdateAPIKey(k APIKey) error
+	TouchAPIKey(id uuid.UUID, t time.Time) error
+	DeleteAPIKey(id uuid.UUID) error
+}
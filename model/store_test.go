@@ -0,0 +1,176 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// testStore is the store of both backends the tests run against.
+type testStore interface {
+	AccountStore
+	BottleStore
+}
+
+// eachStore runs f against a seeded MemoryStore and SQLStore.
+func eachStore(t *testing.T, f func(t *testing.T, s testStore)) {
+	t.Run("memory", func(t *testing.T) {
+		f(t, NewMemoryStore())
+	})
+	t.Run("sql", func(t *testing.T) {
+		s, err := OpenSQLStore(filepath.Join(t.TempDir(), "celler.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		f(t, s)
+	})
+}
+
+func TestInsertKeepsName(t *testing.T) {
+	eachStore(t, func(t *testing.T, s testStore) {
+		id, err := s.Insert(Account{Name: "alice"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := s.AccountOne(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Name != "alice" {
+			t.Errorf("name = %q, want %q", a.Name, "alice")
+		}
+	})
+}
+
+func TestFilterFoldsUnicodeCase(t *testing.T) {
+	eachStore(t, func(t *testing.T, s testStore) {
+		if _, err := s.Insert(Account{Name: "ÉCOLE"}); err != nil {
+			t.Fatal(err)
+		}
+		for _, expr := range []string{`name ieq "école"`, `name isw "éc"`, `name ico "COLE"`, `name iew "cOlE"`} {
+			f, err := ParseFilter(expr, AccountFilterFields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			as, _, err := s.AccountsAll(f, ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(as) != 1 || as[0].Name != "ÉCOLE" {
+				t.Errorf("%s: got %v, want ÉCOLE", expr, as)
+			}
+		}
+	})
+}
+
+func TestSeedUUIDs(t *testing.T) {
+	eachStore(t, func(t *testing.T, s testStore) {
+		for i, u := range []string{
+			"6ba7b810-9dad-41d1-80b4-00c04fd430c8",
+			"6ba7b811-9dad-41d1-80b4-00c04fd430c8",
+			"6ba7b812-9dad-41d1-80b4-00c04fd430c8",
+		} {
+			a, err := s.AccountByUUID(uuid.FromStringOrNil(u))
+			if err != nil {
+				t.Fatalf("%s: %v", u, err)
+			}
+			if a.ID != i+1 {
+				t.Errorf("%s: account %d, want %d", u, a.ID, i+1)
+			}
+			b, err := s.BottleOne(i + 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.AccountUUID == nil || b.AccountUUID.String() != u {
+				t.Errorf("bottle %d: account_uuid %v, want %s", b.ID, b.AccountUUID, u)
+			}
+		}
+	})
+}
+
+// orphan deletes account id of s with the orphan policy, leaving its
+// bottles without owner.
+func orphan(t *testing.T, s testStore, id int) {
+	t.Helper()
+	switch s := s.(type) {
+	case *MemoryStore:
+		s.DeletePolicy = DeleteOrphan
+	case *SQLStore:
+		s.DeletePolicy = DeleteOrphan
+	}
+	if err := s.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenameOrphanedBottle(t *testing.T) {
+	eachStore(t, func(t *testing.T, s testStore) {
+		orphan(t, s, 1)
+		b, err := s.BottleOne(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Account != nil {
+			t.Fatalf("bottle 1 is owned by %d after deleting its account", b.AccountID())
+		}
+		b.Name = "renamed"
+		if err := s.UpdateBottle(*b); err != nil {
+			t.Fatalf("renaming an orphaned bottle: %v", err)
+		}
+		if b, err = s.BottleOne(1); err != nil {
+			t.Fatal(err)
+		}
+		if b.Name != "renamed" || b.Account != nil {
+			t.Errorf("bottle 1 is %q owned by %d, want %q without owner", b.Name, b.AccountID(), "renamed")
+		}
+		b.Account = &Account{ID: 99}
+		if err := s.UpdateBottle(*b); err != ErrOwnerNotFound {
+			t.Errorf("moving to a missing account: %v, want %v", err, ErrOwnerNotFound)
+		}
+	})
+}
+
+func TestOrphanedBottlesFilterAndSort(t *testing.T) {
+	eachStore(t, func(t *testing.T, s testStore) {
+		// Bottle 2 loses its owner and counts as account 0.
+		orphan(t, s, 2)
+		for _, tc := range []struct {
+			filter string
+			sort   []SortKey
+			want   []int
+		}{
+			{`account_id eq 0`, nil, []int{2}},
+			{`not account_id eq 1`, nil, []int{2, 3}},
+			{`account_id lt 3`, nil, []int{1, 2}},
+			{"", []SortKey{{Field: "account_id"}}, []int{2, 1, 3}},
+			{"", []SortKey{{Field: "account_id", Desc: true}}, []int{3, 1, 2}},
+		} {
+			f, err := ParseFilter(tc.filter, BottleFilterFields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Page one bottle at a time so that every cursor is followed.
+			opts := ListOptions{Limit: 1, Sort: tc.sort}
+			var got []int
+			for {
+				bs, page, err := s.BottlesAll(f, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, b := range bs {
+					got = append(got, b.ID)
+				}
+				if page.Next == "" || len(got) > len(tc.want) {
+					break
+				}
+				opts.Cursor = page.Next
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("filter %q sort %v: bottles %v, want %v", tc.filter, tc.sort, got, tc.want)
+			}
+		}
+	})
+}
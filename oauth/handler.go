@@ -0,0 +1,319 @@
+package oauth
+
+import (
+	"crypto/subtle"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/labstack/echo"
+)
+
+// Error is an OAuth2 error response (RFC 6749 section 5.2).
+type Error struct {
+	Code        string `json:"error" example:"invalid_request"`
+	Description string `json:"error_description,omitempty" example:"grant_type is required"`
+	status      int
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+var (
+	errInvalidRequest          = &Error{Code: "invalid_request", Description: "a required parameter is missing or invalid", status: http.StatusBadRequest}
+	errInvalidClient           = &Error{Code: "invalid_client", Description: "client authentication failed", status: http.StatusUnauthorized}
+	errInvalidGrant            = &Error{Code: "invalid_grant", Description: "the grant is invalid, expired or was issued to another client", status: http.StatusBadRequest}
+	errUnauthorizedClient      = &Error{Code: "unauthorized_client", Description: "the client may not use this grant type", status: http.StatusBadRequest}
+	errUnsupportedGrantType    = &Error{Code: "unsupported_grant_type", Description: "grant_type must be client_credentials, password or authorization_code", status: http.StatusBadRequest}
+	errUnsupportedResponseType = &Error{Code: "unsupported_response_type", Description: "response_type must be code or token", status: http.StatusBadRequest}
+	errInvalidScope            = &Error{Code: "invalid_scope", Description: "a requested scope is unknown or not allowed for the client or user", status: http.StatusBadRequest}
+	errAccessDenied            = &Error{Code: "access_denied", Description: "no users can sign in", status: http.StatusForbidden}
+	errPKCERequired            = &Error{Code: "invalid_request", Description: "public clients must send a S256 or plain code_challenge", status: http.StatusBadRequest}
+)
+
+// tokenResponse is a successful access token response (RFC 6749 section 5.1).
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// Register mounts the authorization and token endpoints on g.
+func (s *Server) Register(g *echo.Group) {
+	g.GET("/authorize", s.Authorize)
+	g.POST("/authorize", s.Authorize)
+	g.POST("/token", s.Token)
+}
+
+// Token is the token endpoint. Clients authenticate with Basic credentials
+// or the client_id and client_secret form fields.
+func (s *Server) Token(ctx echo.Context) error {
+	resp, err := s.token(ctx)
+	if err != nil {
+		e, ok := err.(*Error)
+		if !ok {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if e == errInvalidClient {
+			ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+		}
+		return ctx.JSON(e.status, e)
+	}
+	h := ctx.Response().Header()
+	h.Set("Cache-Control", "no-store")
+	h.Set("Pragma", "no-cache")
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) token(ctx echo.Context) (tokenResponse, error) {
+	id, secret, ok := ctx.Request().BasicAuth()
+	if !ok {
+		id, secret = ctx.FormValue("client_id"), ctx.FormValue("client_secret")
+	}
+	switch ctx.FormValue("grant_type") {
+	case "client_credentials":
+		c, err := s.client(id, secret, true)
+		if err != nil {
+			return tokenResponse{}, err
+		}
+		if c.Public() {
+			return tokenResponse{}, errUnauthorizedClient
+		}
+		scopes, err := grantScopes(c, ctx.FormValue("scope"))
+		if err != nil {
+			return tokenResponse{}, err
+		}
+		return s.issue(model.Admin{Name: c.ID}, scopes)
+	case "password":
+		if s.Users == nil {
+			return tokenResponse{}, errUnsupportedGrantType
+		}
+		c, err := s.client(id, secret, true)
+		if err != nil {
+			return tokenResponse{}, err
+		}
+		scopes, err := grantScopes(c, ctx.FormValue("scope"))
+		if err != nil {
+			return tokenResponse{}, err
+		}
+		admin, err := s.Users.AdminByPassword(ctx.FormValue("username"), ctx.FormValue("password"))
+		if err != nil {
+			return tokenResponse{}, errInvalidGrant
+		}
+		if scopes, err = s.userScopes(admin, scopes); err != nil {
+			return tokenResponse{}, err
+		}
+		return s.issue(admin, scopes)
+	case "authorization_code":
+		c, err := s.client(id, secret, true)
+		if err != nil {
+			return tokenResponse{}, err
+		}
+		cd, ok := s.redeem(ctx.FormValue("code"))
+		if !ok || cd.clientID != c.ID {
+			return tokenResponse{}, errInvalidGrant
+		}
+		// RFC 6749 section 4.1.3: a redirect_uri of the authorization
+		// request must be repeated identically.
+		if uri := ctx.FormValue("redirect_uri"); (cd.redirectURISent || uri != "") && uri != cd.redirectURI {
+			return tokenResponse{}, errInvalidGrant
+		}
+		if !verifyPKCE(cd.challenge, cd.challengeMode, ctx.FormValue("code_verifier")) {
+			return tokenResponse{}, errInvalidGrant
+		}
+		return s.issue(cd.admin, cd.admin.Scopes)
+	case "":
+		return tokenResponse{}, errInvalidRequest
+	}
+	return tokenResponse{}, errUnsupportedGrantType
+}
+
+// authorizeRequest holds the parameters of an authorization request.
+type authorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Error               string
+	// CSRFToken is the token of the login form, see validCSRF.
+	CSRFToken string
+}
+
+// Authorize is the authorization endpoint of the code and implicit flows.
+// GET shows a login form; POST authenticates the user against s.Users and
+// redirects back to the client with a code or an access token.
+func (s *Server) Authorize(ctx echo.Context) error {
+	req := authorizeRequest{
+		ResponseType:        ctx.FormValue("response_type"),
+		ClientID:            ctx.FormValue("client_id"),
+		RedirectURI:         ctx.FormValue("redirect_uri"),
+		Scope:               ctx.FormValue("scope"),
+		State:               ctx.FormValue("state"),
+		CodeChallenge:       ctx.FormValue("code_challenge"),
+		CodeChallengeMethod: ctx.FormValue("code_challenge_method"),
+	}
+	c, err := s.client(req.ClientID, "", false)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	uri, ok := redirectURI(c, req.RedirectURI)
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "redirect_uri is not registered for the client")
+	}
+	// From here on errors are reported to the client through the redirect.
+	fragment := req.ResponseType == "token"
+	if req.ResponseType != "code" && req.ResponseType != "token" {
+		return redirect(ctx, uri, false, errorValues(errUnsupportedResponseType, req.State))
+	}
+	scopes, err := grantScopes(c, req.Scope)
+	if err != nil {
+		return redirect(ctx, uri, fragment, errorValues(err.(*Error), req.State))
+	}
+	if req.ResponseType == "code" {
+		if req.CodeChallenge != "" && req.CodeChallengeMethod == "" {
+			req.CodeChallengeMethod = "plain"
+		}
+		if (req.CodeChallenge == "" && c.Public()) || (req.CodeChallenge != "" && req.CodeChallengeMethod != "S256" && req.CodeChallengeMethod != "plain") {
+			return redirect(ctx, uri, false, errorValues(errPKCERequired, req.State))
+		}
+	}
+	if s.Users == nil {
+		return redirect(ctx, uri, fragment, errorValues(errAccessDenied, req.State))
+	}
+	if ctx.Request().Method != http.MethodPost {
+		return renderLogin(ctx, http.StatusOK, req)
+	}
+	if !validCSRF(ctx) {
+		req.Error = "The sign in form has expired, please sign in again."
+		return renderLogin(ctx, http.StatusForbidden, req)
+	}
+	admin, err := s.Users.AdminByPassword(ctx.FormValue("username"), ctx.FormValue("password"))
+	if err != nil {
+		req.Error = "The name or password is incorrect."
+		return renderLogin(ctx, http.StatusUnauthorized, req)
+	}
+	if scopes, err = s.userScopes(admin, scopes); err != nil {
+		return redirect(ctx, uri, fragment, errorValues(err.(*Error), req.State))
+	}
+	v := url.Values{}
+	if req.State != "" {
+		v.Set("state", req.State)
+	}
+	if fragment {
+		resp, err := s.issue(admin, scopes)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		v.Set("access_token", resp.AccessToken)
+		v.Set("token_type", resp.TokenType)
+		v.Set("expires_in", strconv.Itoa(resp.ExpiresIn))
+		v.Set("scope", resp.Scope)
+		return redirect(ctx, uri, true, v)
+	}
+	admin.Scopes = scopes
+	k, err := s.newCode(code{
+		clientID:        c.ID,
+		redirectURI:     uri,
+		redirectURISent: req.RedirectURI != "",
+		admin:           admin,
+		challenge:       req.CodeChallenge,
+		challengeMode:   req.CodeChallengeMethod,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	v.Set("code", k)
+	return redirect(ctx, uri, false, v)
+}
+
+func errorValues(e *Error, state string) url.Values {
+	v := url.Values{"error": {e.Code}, "error_description": {e.Description}}
+	if state != "" {
+		v.Set("state", state)
+	}
+	return v
+}
+
+// redirect sends the user agent back to the client with v in the query or the fragment.
+func redirect(ctx echo.Context, uri string, fragment bool, v url.Values) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if fragment {
+		return ctx.Redirect(http.StatusFound, u.String()+"#"+v.Encode())
+	}
+	q := u.Query()
+	for k, vs := range v {
+		q[k] = vs
+	}
+	u.RawQuery = q.Encode()
+	return ctx.Redirect(http.StatusFound, u.String())
+}
+
+// csrfCookie holds the CSRF token of the login form. A form posted from
+// another site carries neither the token nor, being SameSite, the cookie.
+const (
+	csrfCookie = "oauth_csrf"
+	csrfField  = "csrf_token"
+)
+
+// validCSRF reports whether the posted login form carries the token of its
+// cookie.
+func validCSRF(ctx echo.Context) bool {
+	cookie, err := ctx.Cookie(csrfCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(ctx.FormValue(csrfField))) == 1
+}
+
+func renderLogin(ctx echo.Context, status int, req authorizeRequest) error {
+	t, err := randomString()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	req.CSRFToken = t
+	ctx.SetCookie(&http.Cookie{
+		Name:     csrfCookie,
+		Value:    t,
+		Path:     ctx.Request().URL.Path,
+		Secure:   ctx.IsTLS(),
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	ctx.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	ctx.Response().WriteHeader(status)
+	return loginForm.Execute(ctx.Response(), req)
+}
+
+var loginForm = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in</title></head>
+<body>
+<h1>Sign in to {{.ClientID}}</h1>
+{{if .Scope}}<p>Requested scopes: {{.Scope}}</p>{{end}}
+{{if .Error}}<p style="color: red">{{.Error}}</p>{{end}}
+<form method="post" action="authorize">
+<input type="hidden" name="response_type" value="{{.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Scope}}">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="hidden" name="code_challenge" value="{{.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.CodeChallengeMethod}}">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<p><label>Name <input name="username" autocomplete="username"></label></p>
+<p><label>Password <input name="password" type="password" autocomplete="current-password"></label></p>
+<p><button type="submit">Sign in</button></p>
+</form>
+</body>
+</html>
+`))
@@ -0,0 +1,303 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hexaforce/swagger-echo/auth"
+	"github.com/hexaforce/swagger-echo/model"
+)
+
+// Scopes are the scopes declared by the security definitions of the API.
+var Scopes = model.Scopes
+
+// Defaults for the lifetime of issued credentials.
+const (
+	DefaultTokenTTL = time.Hour
+	DefaultCodeTTL  = 10 * time.Minute
+	// DefaultPurgeInterval is how often expired codes and tokens are dropped.
+	DefaultPurgeInterval = time.Minute
+)
+
+// Client is a registered OAuth2 client.
+type Client struct {
+	ID string
+	// Secret authenticates a confidential client. Public clients have none
+	// and must use PKCE with the authorization code grant.
+	Secret string
+	// RedirectURIs are the exact redirect URIs the client may use.
+	RedirectURIs []string
+	// Scopes the client may request.
+	Scopes []string
+}
+
+// Public reports whether the client has no secret.
+func (c Client) Public() bool {
+	return c.Secret == ""
+}
+
+// Server issues access tokens for the client_credentials, password,
+// implicit and authorization_code grants. Codes and tokens are kept in
+// process memory; expired ones are purged at most every PurgeInterval when
+// new ones are stored. It is safe for concurrent use.
+type Server struct {
+	// Users authenticates resource owners for the password grant and the
+	// login form of the authorization endpoint.
+	Users auth.PasswordStore
+	// UserScopes returns the scopes a user may be granted, e.g. by its
+	// roles. The password, implicit and authorization code grants issue
+	// the requested scopes the user may be granted. Users may be granted
+	// any scope of their client when nil.
+	UserScopes func(admin model.Admin) []string
+	// TokenTTL is the lifetime of access tokens.
+	TokenTTL time.Duration
+	// CodeTTL is the lifetime of authorization codes.
+	CodeTTL time.Duration
+	// PurgeInterval is the minimum time between two purges of expired codes
+	// and tokens.
+	PurgeInterval time.Duration
+
+	mu      sync.Mutex
+	clients map[string]Client
+	codes   map[string]code
+	tokens  map[[sha256.Size]byte]token
+	now     func() time.Time
+	purged  time.Time
+}
+
+type code struct {
+	clientID    string
+	redirectURI string
+	// redirectURISent is whether the authorization request named
+	// redirectURI, which the token request must then repeat.
+	redirectURISent bool
+	admin           model.Admin
+	challenge       string
+	challengeMode   string
+	expires         time.Time
+}
+
+type token struct {
+	admin   model.Admin
+	expires time.Time
+}
+
+// NewServer returns a Server without clients that authenticates users against users.
+func NewServer(users auth.PasswordStore) *Server {
+	return &Server{
+		Users:         users,
+		TokenTTL:      DefaultTokenTTL,
+		CodeTTL:       DefaultCodeTTL,
+		PurgeInterval: DefaultPurgeInterval,
+		clients:       map[string]Client{},
+		codes:         map[string]code{},
+		tokens:        map[[sha256.Size]byte]token{},
+		now:           time.Now,
+	}
+}
+
+// Purge drops the expired codes and tokens.
+func (s *Server) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purge()
+}
+
+// purge drops the expired codes and tokens. s.mu must be held.
+func (s *Server) purge() {
+	now := s.now()
+	for k, c := range s.codes {
+		if !now.Before(c.expires) {
+			delete(s.codes, k)
+		}
+	}
+	for k, t := range s.tokens {
+		if !now.Before(t.expires) {
+			delete(s.tokens, k)
+		}
+	}
+	s.purged = now
+}
+
+// purgeDue purges when the last purge is older than PurgeInterval. s.mu
+// must be held.
+func (s *Server) purgeDue() {
+	if s.now().Sub(s.purged) >= s.PurgeInterval {
+		s.purge()
+	}
+}
+
+// AddClient registers a client, replacing any client with the same ID.
+func (s *Server) AddClient(c Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[c.ID] = c
+}
+
+// AdminByToken implements auth.TokenStore. The returned admin carries the
+// scopes granted to the token.
+func (s *Server) AdminByToken(t string) (model.Admin, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := sha256.Sum256([]byte(t))
+	tok, ok := s.tokens[k]
+	if !ok {
+		return model.Admin{}, auth.ErrInvalidCredentials
+	}
+	if !s.now().Before(tok.expires) {
+		delete(s.tokens, k)
+		return model.Admin{}, auth.ErrInvalidCredentials
+	}
+	return tok.admin, nil
+}
+
+// client looks up a client; secret is checked for confidential clients.
+func (s *Server) client(id, secret string, checkSecret bool) (Client, error) {
+	s.mu.Lock()
+	c, ok := s.clients[id]
+	s.mu.Unlock()
+	if !ok {
+		return Client{}, errInvalidClient
+	}
+	if checkSecret && !c.Public() && subtle.ConstantTimeCompare([]byte(c.Secret), []byte(secret)) != 1 {
+		return Client{}, errInvalidClient
+	}
+	return c, nil
+}
+
+// issue creates an access token for admin with the given scopes.
+func (s *Server) issue(admin model.Admin, scopes []string) (tokenResponse, error) {
+	t, err := randomString()
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	admin.Scopes = scopes
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeDue()
+	s.tokens[sha256.Sum256([]byte(t))] = token{admin: admin, expires: s.now().Add(s.TokenTTL)}
+	return tokenResponse{
+		AccessToken: t,
+		TokenType:   "bearer",
+		ExpiresIn:   int(s.TokenTTL / time.Second),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// newCode stores an authorization code.
+func (s *Server) newCode(c code) (string, error) {
+	k, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	c.expires = s.now().Add(s.CodeTTL)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeDue()
+	s.codes[k] = c
+	return k, nil
+}
+
+// redeem removes and returns an unexpired authorization code. Codes are single use.
+func (s *Server) redeem(k string) (code, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.codes[k]
+	delete(s.codes, k)
+	if !ok || !s.now().Before(c.expires) {
+		return code{}, false
+	}
+	return c, true
+}
+
+// grantScopes resolves the space separated requested scopes against what
+// the client may request. No request grants all the client's scopes.
+func grantScopes(c Client, requested string) ([]string, error) {
+	fields := strings.Fields(requested)
+	if len(fields) == 0 {
+		return append([]string{}, c.Scopes...), nil
+	}
+	scopes := []string{}
+	for _, f := range fields {
+		if !contains(c.Scopes, f) {
+			return nil, errInvalidScope
+		}
+		if !contains(scopes, f) {
+			scopes = append(scopes, f)
+		}
+	}
+	return scopes, nil
+}
+
+// userScopes limits the granted scopes to the ones admin may be granted.
+// Granting none is errInvalidScope, as a token without scopes would not be
+// limited at all.
+func (s *Server) userScopes(admin model.Admin, scopes []string) ([]string, error) {
+	if s.UserScopes == nil {
+		return scopes, nil
+	}
+	allowed := s.UserScopes(admin)
+	granted := []string{}
+	for _, scope := range scopes {
+		if contains(allowed, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	if len(granted) == 0 {
+		return nil, errInvalidScope
+	}
+	return granted, nil
+}
+
+// redirectURI returns the registered redirect URI matching requested,
+// or the only registered one when none is requested.
+func redirectURI(c Client, requested string) (string, bool) {
+	if requested == "" {
+		if len(c.RedirectURIs) == 1 {
+			return c.RedirectURIs[0], true
+		}
+		return "", false
+	}
+	return requested, contains(c.RedirectURIs, requested)
+}
+
+// verifyPKCE checks a code_verifier against the challenge of RFC 7636.
+func verifyPKCE(challenge, method, verifier string) bool {
+	if challenge == "" {
+		return true
+	}
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	switch method {
+	case "S256":
+		sum := sha256.Sum256([]byte(verifier))
+		verifier = base64.RawURLEncoding.EncodeToString(sum[:])
+	case "plain":
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(verifier)) == 1
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
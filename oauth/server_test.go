@@ -0,0 +1,259 @@
+package oauth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hexaforce/swagger-echo/auth"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/labstack/echo"
+)
+
+const (
+	testRedirectURI = "http://client.test/callback"
+	testPassword    = "s3cret"
+)
+
+// newTestServer serves s with the confidential client "app" and the user
+// admin.
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	users := auth.NewMemoryStore()
+	users.AddPassword(testPassword, model.Admin{ID: 1, Name: "admin"})
+	s := NewServer(users)
+	s.AddClient(Client{
+		ID:           "app",
+		Secret:       "app-secret",
+		RedirectURIs: []string{testRedirectURI, testRedirectURI + "/other"},
+		Scopes:       []string{"read", "write", "admin"},
+	})
+	e := echo.New()
+	s.Register(e.Group("/oauth"))
+	ts := httptest.NewServer(e)
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// browser keeps cookies and does not follow redirects.
+func browser(t *testing.T) *http.Client {
+	t.Helper()
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{
+		Jar: jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+var csrfInput = regexp.MustCompile(`name="csrf_token" value="([^"]*)"`)
+
+// signIn shows the login form of the authorization request q and posts it
+// with the password of admin, returning the response to the post.
+func signIn(t *testing.T, hc *http.Client, ts *httptest.Server, q url.Values, csrf func(string) string) *http.Response {
+	t.Helper()
+	res, err := hc.Get(ts.URL + "/oauth/authorize?" + q.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := csrfInput.FindSubmatch(page)
+	if res.StatusCode != http.StatusOK || m == nil {
+		t.Fatalf("login form: status %d without csrf_token", res.StatusCode)
+	}
+	form := url.Values{}
+	for k, v := range q {
+		form[k] = v
+	}
+	form.Set("username", "admin")
+	form.Set("password", testPassword)
+	form.Set("csrf_token", csrf(string(m[1])))
+	res, err = hc.PostForm(ts.URL+"/oauth/authorize", form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func sameToken(token string) string { return token }
+
+func TestLoginCSRF(t *testing.T) {
+	_, ts := newTestServer(t)
+	q := url.Values{"response_type": {"code"}, "client_id": {"app"}, "redirect_uri": {testRedirectURI}}
+
+	if res := signIn(t, browser(t), ts, q, func(string) string { return "forged" }); res.StatusCode != http.StatusForbidden {
+		t.Errorf("forged csrf_token: status %d, want %d", res.StatusCode, http.StatusForbidden)
+	}
+
+	// A cross-site post carries the form fields but not the cookie.
+	form := url.Values{"username": {"admin"}, "password": {testPassword}, "csrf_token": {"forged"}}
+	for k, v := range q {
+		form[k] = v
+	}
+	res, err := browser(t).PostForm(ts.URL+"/oauth/authorize", form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("post without cookie: status %d, want %d", res.StatusCode, http.StatusForbidden)
+	}
+
+	if res := signIn(t, browser(t), ts, q, sameToken); res.StatusCode != http.StatusFound {
+		t.Errorf("valid csrf_token: status %d, want %d", res.StatusCode, http.StatusFound)
+	}
+}
+
+func TestTokenRedirectURI(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	// code returns a code of an authorization request with the given
+	// redirect_uri, none when empty.
+	code := func(redirectURI string) string {
+		q := url.Values{"response_type": {"code"}, "client_id": {"app"}}
+		if redirectURI != "" {
+			q.Set("redirect_uri", redirectURI)
+		}
+		res := signIn(t, browser(t), ts, q, sameToken)
+		loc, err := res.Location()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return loc.Query().Get("code")
+	}
+	exchange := func(code, redirectURI string) int {
+		form := url.Values{"grant_type": {"authorization_code"}, "code": {code}}
+		if redirectURI != "" {
+			form.Set("redirect_uri", redirectURI)
+		}
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/oauth/token", strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.SetBasicAuth("app", "app-secret")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	for _, tc := range []struct {
+		name       string
+		authorize  string
+		token      string
+		wantStatus int
+	}{
+		{"repeated", testRedirectURI, testRedirectURI, http.StatusOK},
+		{"omitted", testRedirectURI, "", http.StatusBadRequest},
+		{"different", testRedirectURI, testRedirectURI + "/other", http.StatusBadRequest},
+	} {
+		if got := exchange(code(tc.authorize), tc.token); got != tc.wantStatus {
+			t.Errorf("%s: status %d, want %d", tc.name, got, tc.wantStatus)
+		}
+	}
+}
+
+func TestUserScopes(t *testing.T) {
+	s, ts := newTestServer(t)
+	s.UserScopes = func(model.Admin) []string { return []string{"read"} }
+
+	password := func(scope string) (int, string) {
+		form := url.Values{"grant_type": {"password"}, "username": {"admin"}, "password": {testPassword}, "scope": {scope}}
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/oauth/token", strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.SetBasicAuth("app", "app-secret")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		var body struct {
+			Scope string `json:"scope"`
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode, body.Scope + body.Error
+	}
+	if status, scope := password("read write"); status != http.StatusOK || scope != "read" {
+		t.Errorf("password grant of read write: %d %q, want 200 read", status, scope)
+	}
+	if status, got := password("admin"); status != http.StatusBadRequest || got != "invalid_scope" {
+		t.Errorf("password grant of admin: %d %q, want 400 invalid_scope", status, got)
+	}
+
+	q := url.Values{"response_type": {"code"}, "client_id": {"app"}, "redirect_uri": {testRedirectURI}, "scope": {"admin"}}
+	loc, err := signIn(t, browser(t), ts, q, sameToken).Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loc.Query().Get("error"); got != "invalid_scope" {
+		t.Errorf("authorization of admin: redirected to %s, want error invalid_scope", loc)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	s := NewServer(nil)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	if _, err := s.issue(model.Admin{Name: "app"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.newCode(code{clientID: "app"}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(s.TokenTTL + time.Second)
+	if _, err := s.issue(model.Admin{Name: "app"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	tokens, codes := len(s.tokens), len(s.codes)
+	s.mu.Unlock()
+	if tokens != 1 || codes != 0 {
+		t.Errorf("after expiry %d tokens and %d codes are kept, want 1 and 0", tokens, codes)
+	}
+
+	// Within PurgeInterval of the last purge nothing is swept until Purge.
+	now = now.Add(s.TokenTTL + time.Second)
+	s.PurgeInterval = 2 * s.TokenTTL
+	if _, err := s.issue(model.Admin{Name: "app"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	tokens = len(s.tokens)
+	s.mu.Unlock()
+	if tokens != 2 {
+		t.Errorf("within PurgeInterval %d tokens are kept, want 2", tokens)
+	}
+	s.Purge()
+	s.mu.Lock()
+	tokens = len(s.tokens)
+	s.mu.Unlock()
+	if tokens != 1 {
+		t.Errorf("after Purge %d tokens are kept, want 1", tokens)
+	}
+}
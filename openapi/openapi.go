@@ -0,0 +1,435 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hexaforce/swagger-echo/spec"
+	"github.com/labstack/echo"
+)
+
+// Version is the OpenAPI version of converted documents.
+const Version = "3.1.0"
+
+// ErrNotSwagger2 is returned for documents that are not swagger 2.0.
+var ErrNotSwagger2 = errors.New("document is not swagger 2.0")
+
+type object = map[string]interface{}
+
+// Convert turns a swagger 2.0 document, as decoded by encoding/json, into
+// an OpenAPI 3.1 document:
+//
+//   - host, basePath and schemes become servers
+//   - definitions become components.schemas and their references follow
+//   - body and formData parameters become the requestBody, multipart/form-data
+//     when a file is uploaded
+//   - response schemas and headers get a content entry per produced media type
+//   - securityDefinitions become components.securitySchemes with the
+//     implicit, password, clientCredentials and authorizationCode OAuth2 flows
+//
+// Formats such as uuid and int64 are kept, examples become JSON Schema
+// examples and files binary strings.
+func Convert(swagger map[string]interface{}) (map[string]interface{}, error) {
+	if swagger["swagger"] != "2.0" {
+		return nil, ErrNotSwagger2
+	}
+	doc := object{"openapi": Version}
+	for _, k := range []string{"info", "tags", "externalDocs", "security"} {
+		if v, ok := swagger[k]; ok {
+			doc[k] = v
+		}
+	}
+	if servers := servers(swagger); len(servers) > 0 {
+		doc["servers"] = servers
+	}
+	consumes := stringList(swagger["consumes"])
+	produces := stringList(swagger["produces"])
+	paths := object{}
+	for path, item := range objectOf(swagger["paths"]) {
+		converted := object{}
+		for method, op := range objectOf(item) {
+			if method == "parameters" {
+				converted[method] = parameters(op)
+				continue
+			}
+			converted[method] = operation(objectOf(op), consumes, produces)
+		}
+		paths[path] = converted
+	}
+	doc["paths"] = paths
+	components := object{}
+	if defs := objectOf(swagger["definitions"]); len(defs) > 0 {
+		schemas := object{}
+		for name, s := range defs {
+			schemas[name] = schema(s)
+		}
+		components["schemas"] = schemas
+	}
+	if defs := objectOf(swagger["securityDefinitions"]); len(defs) > 0 {
+		schemes := object{}
+		for name, s := range defs {
+			schemes[name] = securityScheme(objectOf(s))
+		}
+		components["securitySchemes"] = schemes
+	}
+	if len(components) > 0 {
+		doc["components"] = components
+	}
+	return doc, nil
+}
+
+// JSON serves the document of h converted to OpenAPI 3.1, with the servers
+// of the client of each request.
+func JSON(h *spec.Handler) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		doc, err := Convert(h.Localize(ctx))
+		if err != nil {
+			return err
+		}
+		return ctx.JSONPretty(http.StatusOK, doc, "    ")
+	}
+}
+
+func servers(swagger object) []interface{} {
+	host, _ := swagger["host"].(string)
+	basePath, _ := swagger["basePath"].(string)
+	if host == "" {
+		if basePath == "" {
+			return nil
+		}
+		return []interface{}{object{"url": basePath}}
+	}
+	schemes := stringList(swagger["schemes"])
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	var servers []interface{}
+	for _, scheme := range schemes {
+		servers = append(servers, object{"url": scheme + "://" + host + basePath})
+	}
+	return servers
+}
+
+func operation(op object, consumes, produces []string) object {
+	converted := object{}
+	for k, v := range op {
+		switch k {
+		case "consumes", "produces", "parameters", "responses", "schemes":
+		default:
+			converted[k] = v
+		}
+	}
+	if c := stringList(op["consumes"]); len(c) > 0 {
+		consumes = c
+	}
+	if p := stringList(op["produces"]); len(p) > 0 {
+		produces = p
+	}
+	if len(consumes) == 0 {
+		consumes = []string{echo.MIMEApplicationJSON}
+	}
+	if len(produces) == 0 {
+		produces = []string{echo.MIMEApplicationJSON}
+	}
+	var params []interface{}
+	var body, form []object
+	for _, p := range arrayOf(op["parameters"]) {
+		param := objectOf(p)
+		switch param["in"] {
+		case "body":
+			body = append(body, param)
+		case "formData":
+			form = append(form, param)
+		default:
+			params = append(params, parameter(param))
+		}
+	}
+	if len(params) > 0 {
+		converted["parameters"] = params
+	}
+	switch {
+	case len(body) > 0:
+		converted["requestBody"] = bodyRequest(body[0], consumes)
+	case len(form) > 0:
+		converted["requestBody"] = formRequest(form, consumes)
+	}
+	responses := object{}
+	for code, r := range objectOf(op["responses"]) {
+		responses[code] = response(objectOf(r), produces)
+	}
+	converted["responses"] = responses
+	return converted
+}
+
+func parameters(v interface{}) []interface{} {
+	var params []interface{}
+	for _, p := range arrayOf(v) {
+		params = append(params, parameter(objectOf(p)))
+	}
+	return params
+}
+
+// parameter converts a path, query or header parameter; its type and
+// constraints move to the schema.
+func parameter(p object) object {
+	converted := object{}
+	s := object{}
+	for k, v := range p {
+		switch k {
+		case "name", "in", "description", "required", "deprecated", "example":
+			converted[k] = v
+		case "allowEmptyValue", "collectionFormat":
+		default:
+			if strings.HasPrefix(k, "x-") {
+				converted[k] = v
+			} else {
+				s[k] = v
+			}
+		}
+	}
+	converted["schema"] = schema(s)
+	switch p["collectionFormat"] {
+	case "multi":
+		converted["style"], converted["explode"] = "form", true
+	case "csv":
+		if p["in"] == "query" {
+			converted["style"] = "form"
+		} else {
+			converted["style"] = "simple"
+		}
+		converted["explode"] = false
+	case "ssv":
+		converted["style"] = "spaceDelimited"
+	case "pipes":
+		converted["style"] = "pipeDelimited"
+	}
+	return converted
+}
+
+func bodyRequest(p object, consumes []string) object {
+	content := object{}
+	s := schema(p["schema"])
+	for _, mt := range consumes {
+		content[mt] = object{"schema": s}
+	}
+	r := object{"content": content}
+	if d, ok := p["description"]; ok {
+		r["description"] = d
+	}
+	if req, ok := p["required"].(bool); ok && req {
+		r["required"] = true
+	}
+	return r
+}
+
+// formRequest gathers formData parameters into one object schema.
+// Uploads are always multipart/form-data.
+func formRequest(params []object, consumes []string) object {
+	properties := object{}
+	var required []string
+	upload := false
+	for _, p := range params {
+		s := object{}
+		for k, v := range p {
+			switch k {
+			case "name", "in", "required", "allowEmptyValue", "collectionFormat":
+			default:
+				s[k] = v
+			}
+		}
+		if s["type"] == "file" {
+			upload = true
+		}
+		name, _ := p["name"].(string)
+		properties[name] = schema(s)
+		if req, ok := p["required"].(bool); ok && req {
+			required = append(required, name)
+		}
+	}
+	s := object{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		s["required"] = required
+	}
+	mediaTypes := []string{}
+	for _, mt := range consumes {
+		if mt == echo.MIMEMultipartForm || mt == echo.MIMEApplicationForm {
+			mediaTypes = append(mediaTypes, mt)
+		}
+	}
+	if upload || len(mediaTypes) == 0 {
+		mediaTypes = []string{echo.MIMEMultipartForm}
+	}
+	content := object{}
+	for _, mt := range mediaTypes {
+		content[mt] = object{"schema": s}
+	}
+	r := object{"content": content}
+	if len(required) > 0 {
+		r["required"] = true
+	}
+	return r
+}
+
+func response(r object, produces []string) object {
+	converted := object{}
+	for k, v := range r {
+		switch k {
+		case "schema", "headers", "examples":
+		default:
+			converted[k] = v
+		}
+	}
+	if _, ok := converted["description"]; !ok {
+		converted["description"] = ""
+	}
+	if headers := objectOf(r["headers"]); len(headers) > 0 {
+		h := object{}
+		for name, v := range headers {
+			header := objectOf(v)
+			c := object{}
+			s := object{}
+			for k, v := range header {
+				if k == "description" {
+					c[k] = v
+				} else {
+					s[k] = v
+				}
+			}
+			c["schema"] = schema(s)
+			h[name] = c
+		}
+		converted["headers"] = h
+	}
+	if raw, ok := r["schema"]; ok {
+		s := schema(raw)
+		examples := objectOf(r["examples"])
+		content := object{}
+		for _, mt := range produces {
+			media := object{"schema": s}
+			if ex, ok := examples[mt]; ok {
+				media["example"] = ex
+			}
+			content[mt] = media
+		}
+		converted["content"] = content
+	}
+	return converted
+}
+
+// schema converts a swagger schema to JSON Schema 2020-12.
+func schema(v interface{}) interface{} {
+	s, ok := v.(object)
+	if !ok {
+		return v
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		// Siblings of $ref, such as the type swag adds, are redundant.
+		return object{"$ref": strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)}
+	}
+	converted := object{}
+	for k, v := range s {
+		switch k {
+		case "items", "additionalProperties", "not":
+			converted[k] = schema(v)
+		case "properties":
+			props := object{}
+			for name, p := range objectOf(v) {
+				props[name] = schema(p)
+			}
+			converted[k] = props
+		case "allOf", "anyOf", "oneOf":
+			var all []interface{}
+			for _, s := range arrayOf(v) {
+				all = append(all, schema(s))
+			}
+			converted[k] = all
+		case "example":
+			converted["examples"] = []interface{}{v}
+		case "x-nullable":
+		default:
+			converted[k] = v
+		}
+	}
+	if converted["type"] == "file" {
+		converted["type"] = "string"
+		converted["format"] = "binary"
+		converted["contentMediaType"] = "application/octet-stream"
+	}
+	if nullable, _ := s["x-nullable"].(bool); nullable {
+		if t, ok := converted["type"].(string); ok {
+			converted["type"] = []interface{}{t, "null"}
+		}
+	}
+	return converted
+}
+
+// securityScheme maps a security definition to a security scheme.
+func securityScheme(d object) object {
+	s := object{}
+	if desc, ok := d["description"]; ok {
+		s["description"] = desc
+	}
+	switch d["type"] {
+	case "basic":
+		s["type"], s["scheme"] = "http", "basic"
+	case "apiKey":
+		s["type"], s["name"], s["in"] = "apiKey", d["name"], d["in"]
+	case "oauth2":
+		flow := object{"scopes": d["scopes"]}
+		if flow["scopes"] == nil {
+			flow["scopes"] = object{}
+		}
+		var name string
+		switch d["flow"] {
+		case "implicit":
+			name = "implicit"
+			flow["authorizationUrl"] = d["authorizationUrl"]
+		case "password":
+			name = "password"
+			flow["tokenUrl"] = d["tokenUrl"]
+		case "application":
+			name = "clientCredentials"
+			flow["tokenUrl"] = d["tokenUrl"]
+		case "accessCode":
+			name = "authorizationCode"
+			flow["authorizationUrl"] = d["authorizationUrl"]
+			flow["tokenUrl"] = d["tokenUrl"]
+		}
+		s["type"], s["flows"] = "oauth2", object{name: flow}
+	default:
+		for k, v := range d {
+			s[k] = v
+		}
+	}
+	return s
+}
+
+func objectOf(v interface{}) object {
+	o, _ := v.(object)
+	return o
+}
+
+func arrayOf(v interface{}) []interface{} {
+	a, _ := v.([]interface{})
+	return a
+}
+
+// stringList reads a list of strings, decoded or not.
+func stringList(v interface{}) []string {
+	switch a := v.(type) {
+	case []string:
+		return a
+	case []interface{}:
+		ss := make([]string, 0, len(a))
+		for _, s := range a {
+			if s, ok := s.(string); ok {
+				ss = append(ss, s)
+			}
+		}
+		return ss
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testSwagger = `{
+	"swagger": "2.0",
+	"host": "localhost:1323",
+	"basePath": "/api/v1",
+	"paths": {
+		"/accounts/{id}/images": {
+			"post": {
+				"consumes": ["multipart/form-data"],
+				"parameters": [
+					{"name": "id", "in": "path", "required": true, "type": "integer", "format": "int64"},
+					{"name": "file", "in": "formData", "required": true, "type": "file"}
+				],
+				"responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/Account"}}}
+			}
+		}
+	},
+	"definitions": {
+		"Account": {"type": "object", "properties": {
+			"id": {"type": "integer", "format": "int64", "example": 1},
+			"uuid": {"type": "string", "format": "uuid", "example": "550e8400-e29b-41d4-a716-446655440000"}
+		}}
+	},
+	"securityDefinitions": {
+		"OAuth2Implicit": {"type": "oauth2", "flow": "implicit", "authorizationUrl": "/oauth/authorize", "scopes": {"read": "Grants read access"}},
+		"OAuth2Password": {"type": "oauth2", "flow": "password", "tokenUrl": "/oauth/token", "scopes": {"read": "Grants read access"}},
+		"OAuth2Application": {"type": "oauth2", "flow": "application", "tokenUrl": "/oauth/token", "scopes": {"admin": "Grants admin access"}},
+		"OAuth2AccessCode": {"type": "oauth2", "flow": "accessCode", "authorizationUrl": "/oauth/authorize", "tokenUrl": "/oauth/token", "scopes": {"write": "Grants write access"}}
+	}
+}`
+
+func TestConvert(t *testing.T) {
+	var swagger map[string]interface{}
+	if err := json.Unmarshal([]byte(testSwagger), &swagger); err != nil {
+		t.Fatal(err)
+	}
+	converted, err := Convert(swagger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decode the result again so that it compares as plain JSON.
+	b, err := json.Marshal(converted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		op      = "paths./accounts/{id}/images.post."
+		account = "components.schemas.Account.properties."
+		schemes = "components.securitySchemes."
+	)
+	for _, tt := range []struct {
+		path string
+		want interface{}
+	}{
+		{"openapi", "3.1.0"},
+		{"servers.0.url", "http://localhost:1323/api/v1"},
+		{op + "parameters.0.schema", map[string]interface{}{"type": "integer", "format": "int64"}},
+		{op + "requestBody.required", true},
+		{op + "requestBody.content.multipart/form-data.schema.required", []interface{}{"file"}},
+		{op + "requestBody.content.multipart/form-data.schema.properties.file", map[string]interface{}{
+			"type": "string", "format": "binary", "contentMediaType": "application/octet-stream",
+		}},
+		{op + "responses.200.content.application/json.schema.$ref", "#/components/schemas/Account"},
+		{account + "id", map[string]interface{}{"type": "integer", "format": "int64", "examples": []interface{}{1.0}}},
+		{account + "uuid", map[string]interface{}{
+			"type": "string", "format": "uuid", "examples": []interface{}{"550e8400-e29b-41d4-a716-446655440000"},
+		}},
+		{schemes + "OAuth2Implicit.flows.implicit.authorizationUrl", "/oauth/authorize"},
+		{schemes + "OAuth2Password.flows.password.tokenUrl", "/oauth/token"},
+		{schemes + "OAuth2Application.flows.clientCredentials", map[string]interface{}{
+			"tokenUrl": "/oauth/token", "scopes": map[string]interface{}{"admin": "Grants admin access"},
+		}},
+		{schemes + "OAuth2AccessCode.flows.authorizationCode", map[string]interface{}{
+			"authorizationUrl": "/oauth/authorize", "tokenUrl": "/oauth/token",
+			"scopes": map[string]interface{}{"write": "Grants write access"},
+		}},
+	} {
+		if got := lookup(doc, tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := Convert(map[string]interface{}{"openapi": "3.0.0"}); err != ErrNotSwagger2 {
+		t.Errorf("Convert of OpenAPI 3: %v, want %v", err, ErrNotSwagger2)
+	}
+}
+
+// lookup follows the dotted path through objects and, by index, arrays.
+func lookup(v interface{}, path string) interface{} {
+	for _, k := range strings.Split(path, ".") {
+		switch o := v.(type) {
+		case map[string]interface{}:
+			v = o[k]
+		case []interface{}:
+			i, err := strconv.Atoi(k)
+			if err != nil || i >= len(o) {
+				return nil
+			}
+			v = o[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
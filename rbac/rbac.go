@@ -0,0 +1,265 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hexaforce/swagger-echo/auth"
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/labstack/echo"
+)
+
+// Permission names an action on a kind of resource.
+type Permission string
+
+// Permissions required by the account and bottle routes.
+const (
+	AccountsRead   Permission = "accounts:read"
+	AccountsCreate Permission = "accounts:create"
+	AccountsUpdate Permission = "accounts:update"
+	AccountsDelete Permission = "accounts:delete"
+	BottlesRead    Permission = "bottles:read"
+	BottlesCreate  Permission = "bottles:create"
+	BottlesUpdate  Permission = "bottles:update"
+	BottlesDelete  Permission = "bottles:delete"
+
+	// All grants every permission.
+	All Permission = "*"
+)
+
+// Permissions are all the permissions a route can require.
+var Permissions = []Permission{
+	AccountsRead, AccountsCreate, AccountsUpdate, AccountsDelete,
+	BottlesRead, BottlesCreate, BottlesUpdate, BottlesDelete,
+}
+
+// Role is a named set of permissions.
+type Role struct {
+	// Permissions are granted on every resource.
+	Permissions []Permission `json:"permissions"`
+	// Own are granted only on the resources the principal owns.
+	Own []Permission `json:"own,omitempty"`
+}
+
+// Policy maps principals to roles and roles to permissions.
+type Policy struct {
+	Roles map[string]Role `json:"roles"`
+	// Principals assigns roles by principal name, in addition to the roles
+	// carried by the credentials. Stored API keys, whose names are chosen
+	// by whoever creates them, are not looked up; they carry the roles of
+	// the admin that created them.
+	Principals map[string][]string `json:"principals,omitempty"`
+	// DefaultRoles are held by principals without any role.
+	DefaultRoles []string `json:"default_roles,omitempty"`
+}
+
+// DefaultPolicy has the roles admin, owner and reader. Admins may do
+// anything, owners may create accounts and manage their own, readers may
+// only read. Principals without a role are readers.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Roles: map[string]Role{
+			"admin": {Permissions: []Permission{All}},
+			"owner": {
+				Permissions: []Permission{AccountsRead, AccountsCreate, BottlesRead},
+				Own:         []Permission{AccountsUpdate, AccountsDelete, BottlesCreate, BottlesUpdate, BottlesDelete},
+			},
+			"reader": {Permissions: []Permission{AccountsRead, BottlesRead}},
+		},
+		DefaultRoles: []string{"reader"},
+	}
+}
+
+// LoadPolicy reads a JSON policy from file.
+func LoadPolicy(file string) (*Policy, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePolicy(b)
+}
+
+// ParsePolicy decodes and validates a JSON policy.
+func ParsePolicy(b []byte) (*Policy, error) {
+	var p Policy
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks that the policy refers only to known permissions and defined roles.
+func (p *Policy) Validate() error {
+	for name, r := range p.Roles {
+		for _, perms := range [][]Permission{r.Permissions, r.Own} {
+			for _, perm := range perms {
+				if perm != All && !hasPermission(Permissions, perm) {
+					return fmt.Errorf("role %s: permission %s is unknown", name, perm)
+				}
+			}
+		}
+	}
+	for principal, roles := range p.Principals {
+		for _, role := range roles {
+			if _, ok := p.Roles[role]; !ok {
+				return fmt.Errorf("principal %s: role %s is not defined", principal, role)
+			}
+		}
+	}
+	for _, role := range p.DefaultRoles {
+		if _, ok := p.Roles[role]; !ok {
+			return fmt.Errorf("default role %s is not defined", role)
+		}
+	}
+	return nil
+}
+
+// RolesOf returns the roles held by admin.
+func (p *Policy) RolesOf(admin model.Admin) []string {
+	roles := p.AssignedRoles(admin)
+	if len(roles) == 0 {
+		roles = append(roles, p.DefaultRoles...)
+	}
+	return roles
+}
+
+// AssignedRoles returns the roles carried by the credentials of admin and
+// assigned to its name, without the default roles.
+func (p *Policy) AssignedRoles(admin model.Admin) []string {
+	roles := append([]string{}, admin.Roles...)
+	if admin.KeyID != "" {
+		return roles
+	}
+	for _, role := range p.Principals[admin.Name] {
+		if !hasString(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// ScopesOf returns the scopes admin may be granted: read and write when
+// its roles grant a permission needing them, see Check, and admin when it
+// holds the admin role.
+func (p *Policy) ScopesOf(admin model.Admin) []string {
+	roles := p.RolesOf(admin)
+	var scopes []string
+	for _, name := range roles {
+		r := p.Roles[name]
+		for _, perm := range append(append([]Permission{}, r.Permissions...), r.Own...) {
+			for _, scope := range scopesOf(perm) {
+				if !hasString(scopes, scope) {
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+	}
+	if hasString(roles, "admin") {
+		scopes = append(scopes, "admin")
+	}
+	return scopes
+}
+
+// Owner resolves the ID of the principal owning the resource a request
+// addresses, 0 for none. Its errors are returned to the client as they are.
+type Owner func(ctx echo.Context) (int, error)
+
+// Check reports whether admin holds perm. A permission a role grants only
+// on own resources is checked by calling owner, which may be nil for
+// routes that do not address a single resource. Credentials limited to
+// scopes need the read scope for read permissions and the write scope for
+// all others; the admin scope grants both. A denial is a 403
+// httputil.Problem naming the permission.
+func (p *Policy) Check(admin model.Admin, perm Permission, owner func() (int, error)) error {
+	roles := p.RolesOf(admin)
+	if len(admin.Scopes) > 0 && !hasString(admin.Scopes, scopeOf(perm)) && !hasString(admin.Scopes, "admin") {
+		return denied(perm, roles, "scope "+scopeOf(perm)+" is required for permission "+string(perm))
+	}
+	own := false
+	for _, name := range roles {
+		r := p.Roles[name]
+		if hasPermission(r.Permissions, All) || hasPermission(r.Permissions, perm) {
+			return nil
+		}
+		own = own || hasPermission(r.Own, All) || hasPermission(r.Own, perm)
+	}
+	if own && owner != nil {
+		id, err := owner()
+		if err != nil {
+			return err
+		}
+		if id != 0 && id == admin.ID {
+			return nil
+		}
+		return denied(perm, roles, "permission "+string(perm)+" is granted only on own resources")
+	}
+	return denied(perm, roles, "permission "+string(perm)+" is required")
+}
+
+// Require returns a route middleware admitting only principals that hold
+// perm, see Check. The principal is the admin authenticated by auth.Enforce.
+func (p *Policy) Require(perm Permission, owner Owner) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			admin, ok := auth.Admin(ctx)
+			if !ok {
+				return auth.ErrNoCredentials
+			}
+			var resolve func() (int, error)
+			if owner != nil {
+				resolve = func() (int, error) { return owner(ctx) }
+			}
+			if err := p.Check(admin, perm, resolve); err != nil {
+				return err
+			}
+			return next(ctx)
+		}
+	}
+}
+
+func denied(perm Permission, roles []string, msg string) error {
+	p := httputil.NewProblem(http.StatusForbidden, msg)
+	p.Permission, p.Roles = string(perm), roles
+	return p
+}
+
+// scopeOf is the scope a scoped credential needs for perm.
+func scopeOf(perm Permission) string {
+	switch perm {
+	case AccountsRead, BottlesRead:
+		return "read"
+	}
+	return "write"
+}
+
+// scopesOf are the scopes needed by the permissions perm stands for.
+func scopesOf(perm Permission) []string {
+	if perm == All {
+		return []string{"read", "write"}
+	}
+	return []string{scopeOf(perm)}
+}
+
+func hasPermission(perms []Permission, perm Permission) bool {
+	for _, v := range perms {
+		if v == perm {
+			return true
+		}
+	}
+	return false
+}
+
+func hasString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
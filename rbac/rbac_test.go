@@ -0,0 +1,69 @@
+package rbac
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hexaforce/swagger-echo/model"
+)
+
+func TestCheckScopes(t *testing.T) {
+	p := DefaultPolicy()
+	for _, tc := range []struct {
+		scopes []string
+		perm   Permission
+		ok     bool
+	}{
+		{nil, AccountsDelete, true},
+		{[]string{"read"}, AccountsRead, true},
+		{[]string{"read"}, AccountsDelete, false},
+		{[]string{"write"}, AccountsDelete, true},
+		{[]string{"write"}, AccountsRead, false},
+		{[]string{"admin"}, AccountsRead, true},
+		{[]string{"admin"}, BottlesDelete, true},
+	} {
+		admin := model.Admin{ID: 1, Name: "admin", Roles: []string{"admin"}, Scopes: tc.scopes}
+		if err := p.Check(admin, tc.perm, nil); (err == nil) != tc.ok {
+			t.Errorf("scopes %v, %s: %v, want allowed %v", tc.scopes, tc.perm, err, tc.ok)
+		}
+	}
+}
+
+func TestRolesOf(t *testing.T) {
+	p := DefaultPolicy()
+	p.Principals = map[string][]string{"alice": {"admin"}}
+
+	for _, tc := range []struct {
+		name  string
+		admin model.Admin
+		want  []string
+	}{
+		{"assigned by name", model.Admin{ID: 2, Name: "alice"}, []string{"admin"}},
+		{"carried and assigned", model.Admin{ID: 2, Name: "alice", Roles: []string{"owner"}}, []string{"owner", "admin"}},
+		{"default", model.Admin{ID: 3, Name: "bob"}, []string{"reader"}},
+		// A key named after a principal does not get its roles.
+		{"key named alice", model.Admin{ID: 3, Name: "alice", KeyID: "k1"}, []string{"reader"}},
+		{"key of an owner", model.Admin{ID: 3, Name: "ci", Roles: []string{"owner"}, KeyID: "k2"}, []string{"owner"}},
+	} {
+		if got := p.RolesOf(tc.admin); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: roles %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestScopesOf(t *testing.T) {
+	p := DefaultPolicy()
+	for _, tc := range []struct {
+		roles []string
+		want  []string
+	}{
+		{[]string{"admin"}, []string{"read", "write", "admin"}},
+		{[]string{"owner"}, []string{"read", "write"}},
+		{[]string{"reader"}, []string{"read"}},
+		{nil, []string{"read"}},
+	} {
+		if got := p.ScopesOf(model.Admin{Name: "alice", Roles: tc.roles}); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("roles %v: scopes %v, want %v", tc.roles, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package server
+
+import (
+	"net"
+
+	"github.com/hexaforce/swagger-echo/auth"
+	"github.com/hexaforce/swagger-echo/blob"
+	"github.com/hexaforce/swagger-echo/controller"
+	_ "github.com/hexaforce/swagger-echo/docs"
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/hexaforce/swagger-echo/imageutil"
+	"github.com/hexaforce/swagger-echo/mock"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/oauth"
+	"github.com/hexaforce/swagger-echo/openapi"
+	"github.com/hexaforce/swagger-echo/rbac"
+	"github.com/hexaforce/swagger-echo/spec"
+	"github.com/hexaforce/swagger-echo/token"
+	"github.com/hexaforce/swagger-echo/validate"
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// Config holds the settings of the server, the flags of main.
+type Config struct {
+	// Addr is the address the server listens on. The Swagger UI redirect
+	// URI of the OAuth2 clients is built from it.
+	Addr string
+	// DBPath is the SQLite database file, the in-memory store when empty.
+	DBPath string
+	// BlobDir is the directory uploaded account images are stored in.
+	BlobDir string
+	// MaxImageSize is the maximum size of an uploaded account image in bytes.
+	MaxImageSize int64
+	// ThumbnailSizes are the comma separated name=max thumbnail sizes.
+	ThumbnailSizes string
+	// AdminKey is the bootstrap API key of the admin user, disabled when empty.
+	AdminKey string
+	// AdminPassword is the Basic auth password of the admin user, disabled when empty.
+	AdminPassword string
+	// OAuthClientSecret is the secret of the confidential OAuth2 client
+	// celler, disabled when empty.
+	OAuthClientSecret string
+	// JWTAlg is the signing algorithm of admin JWTs, HS256 or RS256.
+	JWTAlg string
+	// JWTKey is the file with the HS256 secret or the PEM RS256 private key,
+	// a random HS256 secret when empty.
+	JWTKey string
+	// RBACPolicy is the JSON file with the roles and permissions, the
+	// default policy when empty.
+	RBACPolicy string
+	// Spec is the swagger 2.0 document enforced, the generated docs when empty.
+	Spec string
+	// ValidateRequests checks parameters and bodies against the spec.
+	ValidateRequests bool
+	// ValidateResponses is the spec.ResponseMode of the response check, off when empty.
+	ValidateResponses string
+	// StrictRoutes refuses to start when the routes and the spec drift apart.
+	StrictRoutes bool
+	// Mock answers the operations of the spec with synthesized responses.
+	Mock bool
+	// MockState keeps the resources created in mock mode.
+	MockState bool
+	// DeletePolicy is what deleting an account does to its bottles.
+	DeletePolicy string
+}
+
+// DefaultConfig returns the defaults of the flags of main.
+func DefaultConfig() Config {
+	return Config{
+		Addr:             ":1323",
+		BlobDir:          "blobs",
+		MaxImageSize:     imageutil.DefaultMaxSize,
+		ThumbnailSizes:   "thumb=128,medium=512",
+		JWTAlg:           "HS256",
+		ValidateRequests: true,
+		DeletePolicy:     string(model.DeleteRestrict),
+	}
+}
+
+// Server is the celler API mounted on an echo instance.
+type Server struct {
+	close func() error
+}
+
+// New mounts the API configured by cfg on e: the middleware, the routes of
+// the API below /api/v1, the OAuth2 endpoints and the published spec.
+func New(e *echo.Echo, cfg Config) (_ *Server, err error) {
+	var closeStore func() error
+	defer func() {
+		if err != nil && closeStore != nil {
+			closeStore()
+		}
+	}()
+
+	e.HTTPErrorHandler = httputil.ErrorHandler
+	e.Validator = validate.New()
+	e.Binder = &validate.Binder{}
+
+	// Middleware
+	e.Use(middleware.RequestID())
+	e.Use(middleware.Logger())
+	e.Use(middleware.Recover())
+
+	// Store
+	var store interface {
+		model.AccountStore
+		model.BottleStore
+		model.ImageStore
+		model.APIKeyStore
+	}
+	policy, err := model.ParseDeletePolicy(cfg.DeletePolicy)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DBPath == "" {
+		s := model.NewMemoryStore()
+		s.DeletePolicy = policy
+		store = s
+	} else {
+		s, err := model.OpenSQLStore(cfg.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		closeStore = s.Close
+		s.DeletePolicy = policy
+		store = s
+	}
+
+	blobs, err := blob.NewLocalStore(cfg.BlobDir)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes, err := imageutil.ParseSizes(cfg.ThumbnailSizes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Credentials
+	credentials := auth.NewMemoryStore()
+	adminUser := model.Admin{ID: 1, Name: "admin", Roles: []string{"admin"}}
+	if cfg.AdminKey != "" {
+		credentials.AddAPIKey(cfg.AdminKey, adminUser)
+	}
+	if cfg.AdminPassword != "" {
+		credentials.AddPassword(cfg.AdminPassword, adminUser)
+	}
+
+	// Stored API keys
+	keys := auth.NewKeyStore(store)
+
+	// JWT, revoked together with the API key they were issued for
+	tokens, err := token.Load(cfg.JWTAlg, cfg.JWTKey)
+	if err != nil {
+		return nil, err
+	}
+	tokens.Keys = keys
+
+	// Roles and permissions
+	authz := rbac.DefaultPolicy()
+	if cfg.RBACPolicy != "" {
+		if authz, err = rbac.LoadPolicy(cfg.RBACPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	// OAuth2 server, granting users only the scopes of their roles
+	oauthServer := oauth.NewServer(credentials)
+	oauthServer.UserScopes = authz.ScopesOf
+	redirectURI := swaggerRedirectURI(cfg.Addr)
+	oauthServer.AddClient(oauth.Client{
+		ID:           "swagger-ui",
+		RedirectURIs: []string{redirectURI},
+		Scopes:       oauth.Scopes,
+	})
+	if cfg.OAuthClientSecret != "" {
+		oauthServer.AddClient(oauth.Client{
+			ID:           "celler",
+			Secret:       cfg.OAuthClientSecret,
+			RedirectURIs: []string{redirectURI},
+			Scopes:       oauth.Scopes,
+		})
+	}
+
+	// Controller
+	c := controller.NewController(store, store, store, blobs, store)
+	c.MaxImageSize = cfg.MaxImageSize
+	c.ThumbnailSizes = sizes
+	c.Tokens = tokens
+	c.Policy = authz
+
+	// API spec
+	doc, err := spec.Read(cfg.Spec)
+	if err != nil {
+		return nil, err
+	}
+	api, err := spec.Parse(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Responses declared by the @Success and @Failure annotations
+	if cfg.ValidateResponses != "" {
+		mode, err := spec.ParseResponseMode(cfg.ValidateResponses)
+		if err != nil {
+			return nil, err
+		}
+		e.Use(spec.ValidateResponses(api, mode))
+	}
+
+	// Access control declared by the @Security annotations
+	security, err := auth.NewSpec(api)
+	if err != nil {
+		return nil, err
+	}
+	e.Use(auth.Enforce(auth.Config{
+		APIKeys:   auth.APIKeyStores{keys, credentials},
+		Passwords: credentials,
+		Tokens:    auth.TokenStores{tokens, oauthServer},
+	}, security))
+
+	// Parameters declared by the @Param annotations
+	if cfg.ValidateRequests {
+		e.Use(spec.ValidateRequests(api))
+	}
+
+	// Routes, or responses synthesized from the spec
+	v1 := e.Group("/api/v1")
+	if cfg.Mock {
+		m := mock.New(api)
+		m.Stateful = cfg.MockState
+		m.Register(v1)
+	} else {
+		accounts := v1.Group("/accounts")
+		{
+			accounts.GET("/:id", c.ShowAccount, authz.Require(rbac.AccountsRead, c.AccountOwner))
+			accounts.GET("", c.ListAccounts, authz.Require(rbac.AccountsRead, nil))
+			accounts.POST("", c.AddAccount, authz.Require(rbac.AccountsCreate, nil))
+			accounts.DELETE("/:id", c.DeleteAccount, authz.Require(rbac.AccountsDelete, c.AccountOwner))
+			accounts.PATCH("/:id", c.UpdateAccount, authz.Require(rbac.AccountsUpdate, c.AccountOwner))
+			accounts.POST("/:id/images", c.UploadAccountImage, authz.Require(rbac.AccountsUpdate, c.AccountOwner))
+			accounts.GET("/:id/images", c.ListAccountImages, authz.Require(rbac.AccountsRead, c.AccountOwner))
+			accounts.GET("/:id/images/:image_id", c.ShowAccountImage, authz.Require(rbac.AccountsRead, c.AccountOwner))
+			accounts.DELETE("/:id/images/:image_id", c.DeleteAccountImage, authz.Require(rbac.AccountsUpdate, c.AccountOwner))
+			accounts.GET("/:id/bottles", c.ListAccountBottles, authz.Require(rbac.BottlesRead, c.AccountOwner))
+			accounts.POST("/:id/bottles", c.AddAccountBottle, authz.Require(rbac.BottlesCreate, c.AccountOwner))
+			accounts.GET("/:id/bottles/:bottle_id", c.ShowAccountBottle, authz.Require(rbac.BottlesRead, c.AccountOwner))
+		}
+		bottles := v1.Group("/bottles")
+		{
+			bottles.GET("/:id", c.ShowBottle, authz.Require(rbac.BottlesRead, c.BottleOwner))
+			bottles.GET("", c.ListBottles, authz.Require(rbac.BottlesRead, nil))
+			bottles.POST("", c.AddBottle, authz.Require(rbac.BottlesCreate, c.BottleAccountOwner))
+			bottles.PUT("/:id", c.ReplaceBottle, authz.Require(rbac.BottlesUpdate, c.BottleOwner))
+			bottles.PATCH("/:id", c.UpdateBottle, authz.Require(rbac.BottlesUpdate, c.BottleOwner))
+			bottles.DELETE("/:id", c.DeleteBottle, authz.Require(rbac.BottlesDelete, c.BottleOwner))
+		}
+		admin := v1.Group("/admin")
+		{
+			admin.POST("/auth", c.Auth)
+			admin.POST("/refresh", c.RefreshAuth)
+			admin.POST("/revoke", c.RevokeAuth)
+			admin.GET("/keys", c.ListAPIKeys)
+			admin.POST("/keys", c.AddAPIKey)
+			admin.POST("/keys/:id/rotate", c.RotateAPIKey)
+			admin.DELETE("/keys/:id", c.RevokeAPIKey)
+			admin.GET("/drift", c.ShowDrift)
+		}
+		examples := v1.Group("/examples")
+		{
+			examples.GET("/ping", c.PingExample)
+			examples.GET("/calc", c.CalcExample)
+			examples.GET("/groups/:group_id/accounts/:account_id", c.PathParamsExample)
+			examples.GET("/header", c.HeaderExample)
+			examples.GET("/securities", c.SecuritiesExample)
+			examples.GET("/attribute", c.AttributeExample)
+		}
+	}
+
+	// OAuth2 endpoints
+	oauthServer.Register(e.Group("/oauth"))
+
+	// API spec as seen by each client, also read by the swagger UI, and
+	// converted to OpenAPI 3.1
+	published, err := spec.NewHandler(doc)
+	if err != nil {
+		return nil, err
+	}
+	e.GET("/swagger/doc.json", published.JSON)
+	e.GET("/openapi.yaml", published.YAML)
+	e.GET("/openapi.json", openapi.JSON(published))
+
+	// Routes missing from the spec or the other way round
+	c.Spec = api
+	c.Routes = e.Routes
+	if err := api.Drift(e.Routes()).Err(); err != nil {
+		if cfg.StrictRoutes {
+			return nil, err
+		}
+		e.Logger.Error(err)
+	}
+	return &Server{close: closeStore}, nil
+}
+
+// Close releases the store of the server.
+func (s *Server) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	return s.close()
+}
+
+// swaggerRedirectURI is the OAuth2 redirect URI of the Swagger UI served by
+// a server listening on addr, on localhost when addr names no host.
+func swaggerRedirectURI(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, ""
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	return "http://" + host + "/swagger/oauth2-redirect.html"
+}
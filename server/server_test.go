@@ -0,0 +1,401 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/hexaforce/swagger-echo/model"
+	"github.com/hexaforce/swagger-echo/spec"
+	"github.com/labstack/echo"
+)
+
+const testAdminKey = "test-admin-key"
+
+// newTestServer runs the API with the in-memory store and the admin key
+// testAdminKey.
+func newTestServer(t *testing.T, cfg Config) *httptest.Server {
+	t.Helper()
+	cfg.BlobDir = t.TempDir()
+	if cfg.AdminKey == "" {
+		cfg.AdminKey = testAdminKey
+	}
+	e := echo.New()
+	srv, err := New(e, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(e)
+	t.Cleanup(func() {
+		ts.Close()
+		srv.Close()
+	})
+	return ts
+}
+
+// do sends a JSON request authenticated with testAdminKey.
+func do(method, url, body string) (*http.Response, error) {
+	return doAs(testAdminKey, method, url, body)
+}
+
+// doAs sends a JSON request with the Authorization header authorization.
+func doAs(authorization, method, url, body string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(echo.HeaderAuthorization, authorization)
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// expect fails t unless the response has status want and decodes its JSON
+// body into v unless v is nil.
+func expect(t *testing.T, res *http.Response, err error, want int, v interface{}) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != want {
+		t.Fatalf("%s %s: status %d, want %d", res.Request.Method, res.Request.URL.Path, res.StatusCode, want)
+	}
+	if v != nil {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConcurrentAccounts(t *testing.T) {
+	ts := newTestServer(t, DefaultConfig())
+	const n = 64
+
+	var wg sync.WaitGroup
+	ids := make(chan int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := do(http.MethodPost, ts.URL+"/api/v1/accounts", `{"name":"account `+strconv.Itoa(i)+`"}`)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer res.Body.Close()
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("POST /accounts: status %d", res.StatusCode)
+				return
+			}
+			var a model.Account
+			if err := json.NewDecoder(res.Body).Decode(&a); err != nil {
+				t.Error(err)
+				return
+			}
+			ids <- a.ID
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := map[int]bool{}
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("account id %d was created twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("created %d accounts, want %d", len(seen), n)
+	}
+
+	for id := range seen {
+		wg.Add(2)
+		go func(id int) {
+			defer wg.Done()
+			res, err := do(http.MethodDelete, ts.URL+"/api/v1/accounts/"+strconv.Itoa(id), "")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusNoContent {
+				t.Errorf("DELETE /accounts/%d: status %d", id, res.StatusCode)
+			}
+		}(id)
+		go func() {
+			defer wg.Done()
+			res, err := do(http.MethodGet, ts.URL+"/api/v1/accounts", "")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("GET /accounts: status %d", res.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	res, err := do(http.MethodGet, ts.URL+"/api/v1/accounts", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var as []model.Account
+	if err := json.NewDecoder(res.Body).Decode(&as); err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 3 {
+		t.Fatalf("got %d accounts after deleting, want the 3 seeded ones", len(as))
+	}
+}
+
+func TestBottleOwnerUUID(t *testing.T) {
+	ts := newTestServer(t, DefaultConfig())
+	const account2 = "6ba7b811-9dad-41d1-80b4-00c04fd430c8"
+
+	for _, tc := range []struct {
+		body   string
+		status int
+	}{
+		{`{"name":"by uuid","account_uuid":"` + account2 + `"}`, http.StatusOK},
+		{`{"name":"by both","account_id":2,"account_uuid":"` + account2 + `"}`, http.StatusOK},
+		{`{"name":"mismatch","account_id":1,"account_uuid":"` + account2 + `"}`, http.StatusBadRequest},
+		{`{"name":"unknown","account_uuid":"6ba7b8ff-9dad-41d1-80b4-00c04fd430c8"}`, http.StatusBadRequest},
+		{`{"name":"no owner"}`, http.StatusBadRequest},
+	} {
+		res, err := do(http.MethodPost, ts.URL+"/api/v1/bottles", tc.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b model.Bottle
+		err = json.NewDecoder(res.Body).Decode(&b)
+		res.Body.Close()
+		if res.StatusCode != tc.status {
+			t.Errorf("POST /bottles %s: status %d, want %d", tc.body, res.StatusCode, tc.status)
+			continue
+		}
+		if tc.status != http.StatusOK {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.AccountID() != 2 || b.AccountUUID == nil || b.AccountUUID.String() != account2 {
+			t.Errorf("POST /bottles %s: owner %d %v, want 2 %s", tc.body, b.AccountID(), b.AccountUUID, account2)
+		}
+	}
+
+	res, err := do(http.MethodPatch, ts.URL+"/api/v1/bottles/1", `{"account_uuid":"`+account2+`"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var b model.Bottle
+	if err := json.NewDecoder(res.Body).Decode(&b); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || b.AccountID() != 2 {
+		t.Errorf("PATCH /bottles/1: status %d, owner %d, want 200 and 2", res.StatusCode, b.AccountID())
+	}
+}
+
+func TestTokensDieWithAPIKey(t *testing.T) {
+	ts := newTestServer(t, DefaultConfig())
+	api := ts.URL + "/api/v1"
+
+	var key model.NewAPIKey
+	res, err := do(http.MethodPost, api+"/admin/keys", `{"name":"ci","scopes":["read"]}`)
+	expect(t, res, err, http.StatusOK, &key)
+	var pair model.AdminToken
+	res, err = doAs(key.Key, http.MethodPost, api+"/admin/auth", "")
+	expect(t, res, err, http.StatusOK, &pair)
+	res, err = doAs("Bearer "+pair.AccessToken, http.MethodGet, api+"/accounts", "")
+	expect(t, res, err, http.StatusOK, nil)
+
+	res, err = do(http.MethodDelete, api+"/admin/keys/"+key.ID.String(), "")
+	expect(t, res, err, http.StatusNoContent, nil)
+	res, err = doAs("Bearer "+pair.AccessToken, http.MethodGet, api+"/accounts", "")
+	expect(t, res, err, http.StatusUnauthorized, nil)
+	res, err = do(http.MethodPost, api+"/admin/refresh", `{"refresh_token":"`+pair.RefreshToken+`"}`)
+	expect(t, res, err, http.StatusUnauthorized, nil)
+}
+
+func TestAPIKeyActsWithCreatorRoles(t *testing.T) {
+	ts := newTestServer(t, DefaultConfig())
+	api := ts.URL + "/api/v1"
+
+	res, err := do(http.MethodPost, api+"/admin/keys", `{"name":"ci","scopes":["read","write"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key model.NewAPIKey
+	err = json.NewDecoder(res.Body).Decode(&key)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key.Roles) != 1 || key.Roles[0] != "admin" {
+		t.Fatalf("key of the admin has roles %v, want [admin]", key.Roles)
+	}
+
+	// Deleting is an admin permission that readers lack.
+	res, err = doAs(key.Key, http.MethodPost, api+"/accounts", `{"name":"doomed"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a model.Account
+	err = json.NewDecoder(res.Body).Decode(&a)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = doAs(key.Key, http.MethodDelete, api+"/accounts/"+strconv.Itoa(a.ID), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE /accounts/%d with the key: status %d, want %d", a.ID, res.StatusCode, http.StatusNoContent)
+	}
+}
+
+func TestScopedKeyCannotEscalate(t *testing.T) {
+	ts := newTestServer(t, DefaultConfig())
+	api := ts.URL + "/api/v1"
+
+	// The keys of the admin carry its admin role but act within their scopes.
+	var reader, manager model.NewAPIKey
+	res, err := do(http.MethodPost, api+"/admin/keys", `{"name":"reader","scopes":["read"]}`)
+	expect(t, res, err, http.StatusOK, &reader)
+	res, err = do(http.MethodPost, api+"/admin/keys", `{"name":"manager","scopes":["admin","read"]}`)
+	expect(t, res, err, http.StatusOK, &manager)
+
+	res, err = doAs(reader.Key, http.MethodPost, api+"/accounts", `{"name":"denied"}`)
+	expect(t, res, err, http.StatusForbidden, nil)
+	res, err = doAs(reader.Key, http.MethodPost, api+"/admin/keys", `{"name":"escalated","scopes":["admin"]}`)
+	expect(t, res, err, http.StatusForbidden, nil)
+	res, err = doAs(reader.Key, http.MethodDelete, api+"/admin/keys/"+manager.ID.String(), "")
+	expect(t, res, err, http.StatusForbidden, nil)
+	res, err = doAs(reader.Key, http.MethodGet, api+"/admin/drift", "")
+	expect(t, res, err, http.StatusForbidden, nil)
+
+	// A key with the admin scope grants only the scopes it holds.
+	res, err = doAs(manager.Key, http.MethodPost, api+"/admin/keys", `{"name":"writer","scopes":["write"]}`)
+	expect(t, res, err, http.StatusForbidden, nil)
+	res, err = doAs(manager.Key, http.MethodPost, api+"/admin/keys", `{"name":"reader","scopes":["read"]}`)
+	expect(t, res, err, http.StatusOK, nil)
+	res, err = doAs(manager.Key, http.MethodPost, api+"/admin/keys/"+reader.ID.String()+"/rotate", "")
+	expect(t, res, err, http.StatusOK, nil)
+}
+
+func TestValidateResponsesFail(t *testing.T) {
+	// The spec declares no 200 response for the ping example, so its pong
+	// breaks the spec while the other handlers keep to it.
+	doc, err := spec.Read("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d map[string]interface{}
+	if err := json.Unmarshal(doc, &d); err != nil {
+		t.Fatal(err)
+	}
+	ping := d["paths"].(map[string]interface{})["/examples/ping"].(map[string]interface{})["get"].(map[string]interface{})
+	delete(ping["responses"].(map[string]interface{}), "200")
+	if doc, err = json.Marshal(d); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.Spec = filepath.Join(t.TempDir(), "swagger.json")
+	if err := ioutil.WriteFile(cfg.Spec, doc, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg.ValidateResponses = string(spec.ResponseFail)
+	ts := newTestServer(t, cfg)
+
+	get := func(path string, wantStatus int, wantType string) []byte {
+		t.Helper()
+		res, err := do(http.MethodGet, ts.URL+"/api/v1"+path, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != wantStatus || !strings.HasPrefix(res.Header.Get(echo.HeaderContentType), wantType) {
+			t.Fatalf("GET %s: status %d %s, want %d %s: %s", path, res.StatusCode, res.Header.Get(echo.HeaderContentType), wantStatus, wantType, b)
+		}
+		return b
+	}
+
+	var p httputil.Problem
+	body := get("/examples/ping", http.StatusInternalServerError, httputil.MIMEProblemJSON)
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Rule != "status" || strings.Contains(string(body), "pong") {
+		t.Errorf("undeclared status: %s", body)
+	}
+
+	// Responses that keep to the spec are buffered and sent unchanged.
+	var a model.Account
+	if err := json.Unmarshal(get("/accounts/1", http.StatusOK, echo.MIMEApplicationJSON), &a); err != nil || a.ID != 1 {
+		t.Errorf("GET /accounts/1: %+v, %v", a, err)
+	}
+	var name string
+	if err := json.Unmarshal(get("/examples/securities", http.StatusOK, echo.MIMEApplicationJSON), &name); err != nil {
+		t.Errorf("GET /examples/securities: %v", err)
+	}
+	if err := json.Unmarshal(get("/accounts/999", http.StatusNotFound, httputil.MIMEProblemJSON), &p); err != nil || p.Status != http.StatusNotFound {
+		t.Errorf("GET /accounts/999: %+v, %v", p, err)
+	}
+}
+
+func TestRoutesMatchSpec(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BlobDir = t.TempDir()
+	e := echo.New()
+	srv, err := New(e, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	doc, err := spec.Read("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, err := spec.Parse(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := api.Drift(e.Routes()); !d.Empty() {
+		t.Errorf("routes and spec drift: %s", d)
+	}
+}
+
+func TestSwaggerRedirectURI(t *testing.T) {
+	for addr, want := range map[string]string{
+		":1323":          "http://localhost:1323/swagger/oauth2-redirect.html",
+		"0.0.0.0:8080":   "http://localhost:8080/swagger/oauth2-redirect.html",
+		"[::]:8080":      "http://localhost:8080/swagger/oauth2-redirect.html",
+		"127.0.0.1:9000": "http://127.0.0.1:9000/swagger/oauth2-redirect.html",
+		"api.test:80":    "http://api.test:80/swagger/oauth2-redirect.html",
+	} {
+		if got := swaggerRedirectURI(addr); got != want {
+			t.Errorf("swaggerRedirectURI(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package spec
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// anyMethods are the methods echo.Any routes. echo.Group.Use routes them
+// all on the group prefix and its subpaths, so that the group middleware
+// runs for requests no route matches; such fallbacks are not API routes.
+var anyMethods = []string{
+	http.MethodConnect, http.MethodDelete, http.MethodGet, http.MethodHead, http.MethodOptions,
+	http.MethodPatch, http.MethodPost, echo.PROPFIND, http.MethodPut, http.MethodTrace,
+}
+
+// Endpoint is a method and path of a route or an operation.
+type Endpoint struct {
+	Method string `json:"method" example:"GET"`
+	Path   string `json:"path" example:"/accounts/{id}"`
+}
+
+// Mismatch is a route and an operation on the same path whose path
+// parameters differ.
+type Mismatch struct {
+	Method string `json:"method" example:"GET"`
+	// Route is the echo path below the basePath, Spec the documented path.
+	Route  string `json:"route" example:"/accounts/:account_id"`
+	Spec   string `json:"spec" example:"/accounts/{id}"`
+	Reason string `json:"reason" example:"path parameter 1 is account_id in the route and id in the spec"`
+}
+
+// Drift example
+type Drift struct {
+	// Missing are routes the spec does not document.
+	Missing []Endpoint `json:"missing"`
+	// Extra are operations of the spec no route serves.
+	Extra []Endpoint `json:"extra"`
+	// Mismatched are routes documented with other path parameters.
+	Mismatched []Mismatch `json:"mismatched"`
+}
+
+// Empty reports whether routes and spec agree.
+func (d *Drift) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Mismatched) == 0
+}
+
+// Err returns the drift as an error, nil when there is none.
+func (d *Drift) Err() error {
+	if d.Empty() {
+		return nil
+	}
+	return fmt.Errorf("routes and spec drift: %s", d)
+}
+
+func (d *Drift) String() string {
+	var parts []string
+	for _, e := range d.Missing {
+		parts = append(parts, "undocumented route "+e.Method+" "+e.Path)
+	}
+	for _, e := range d.Extra {
+		parts = append(parts, "unrouted operation "+e.Method+" "+e.Path)
+	}
+	for _, m := range d.Mismatched {
+		parts = append(parts, m.Method+" "+m.Spec+": "+m.Reason)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Drift compares routes, e.g. echo.Echo.Routes, with the operations of the
+// document. Only routes below the basePath, apart from the fallbacks
+// echo.Group routes every method to, are API routes; they are compared by method and path with the
+// parameter names left out, and the parameter names of a matching route and
+// operation must agree with each other and with the path parameters the
+// operation declares.
+func (d *Document) Drift(routes []*echo.Route) *Drift {
+	drift := &Drift{Missing: []Endpoint{}, Extra: []Endpoint{}, Mismatched: []Mismatch{}}
+	basePath := strings.TrimSuffix(d.BasePath, "/")
+	ops := map[string]*Operation{}
+	for _, op := range d.operations {
+		ops[op.Method+" "+shape(op.Path, "{")] = op
+	}
+	fallback := fallbacks(routes)
+	routed := map[string]bool{}
+	for _, r := range routes {
+		if name, ok := fallback[r.Path]; !strings.HasPrefix(r.Path, basePath+"/") || (ok && r.Name == name) {
+			continue
+		}
+		path := strings.TrimPrefix(r.Path, basePath)
+		key := r.Method + " " + shape(path, ":")
+		if routed[key] {
+			continue
+		}
+		routed[key] = true
+		op, ok := ops[key]
+		if !ok {
+			drift.Missing = append(drift.Missing, Endpoint{Method: r.Method, Path: path})
+			continue
+		}
+		if reason := mismatch(path, op); reason != "" {
+			drift.Mismatched = append(drift.Mismatched, Mismatch{Method: r.Method, Route: path, Spec: op.Path, Reason: reason})
+		}
+	}
+	for key, op := range ops {
+		if !routed[key] {
+			drift.Extra = append(drift.Extra, Endpoint{Method: op.Method, Path: op.Path})
+		}
+	}
+	sortEndpoints(drift.Missing)
+	sortEndpoints(drift.Extra)
+	sort.Slice(drift.Mismatched, func(i, j int) bool {
+		a, b := drift.Mismatched[i], drift.Mismatched[j]
+		return a.Spec+" "+a.Method < b.Spec+" "+b.Method
+	})
+	return drift
+}
+
+// fallbacks returns, by path, the handler name of the routes that serve a
+// path for every method of anyMethods. Routes added on the same path later,
+// such as the routes of a group on its prefix, replace the fallback of
+// their method and keep their own name.
+func fallbacks(routes []*echo.Route) map[string]string {
+	names := map[string]map[string]string{}
+	for _, r := range routes {
+		if names[r.Path] == nil {
+			names[r.Path] = map[string]string{}
+		}
+		names[r.Path][r.Method] = r.Name
+	}
+	paths := map[string]string{}
+	for path, byMethod := range names {
+		all := true
+		for _, m := range anyMethods {
+			_, ok := byMethod[m]
+			all = all && ok
+		}
+		if all {
+			paths[path] = byMethod[echo.PROPFIND]
+		}
+	}
+	return paths
+}
+
+// mismatch explains how the path parameters of route and op differ.
+func mismatch(route string, op *Operation) string {
+	routeParams, specParams := params(route, ":"), params(op.Path, "{")
+	for i := range routeParams {
+		if routeParams[i] != specParams[i] {
+			return fmt.Sprintf("path parameter %d is %s in the route and %s in the spec", i+1, routeParams[i], specParams[i])
+		}
+	}
+	declared := map[string]bool{}
+	for _, p := range op.Parameters {
+		if p.In != "path" {
+			continue
+		}
+		declared[p.Name] = true
+		if !hasString(specParams, p.Name) {
+			return "path parameter " + p.Name + " is declared but not in the path"
+		}
+	}
+	for _, name := range specParams {
+		if !declared[name] {
+			return "path parameter " + name + " is not declared"
+		}
+	}
+	return ""
+}
+
+// shape is path with every parameter replaced by {}.
+func shape(path, marker string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if isParam(seg, marker) {
+			segs[i] = "{}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// params are the names of the parameters of path.
+func params(path, marker string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if isParam(seg, marker) {
+			names = append(names, strings.Trim(seg, ":{}"))
+		}
+	}
+	return names
+}
+
+func isParam(seg, marker string) bool {
+	if marker == "{" {
+		return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+	}
+	return strings.HasPrefix(seg, ":") || seg == "*"
+}
+
+func sortEndpoints(es []Endpoint) {
+	sort.Slice(es, func(i, j int) bool {
+		if es[i].Path != es[j].Path {
+			return es[i].Path < es[j].Path
+		}
+		return es[i].Method < es[j].Method
+	})
+}
+
+func hasString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
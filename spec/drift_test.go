@@ -0,0 +1,57 @@
+package spec
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+const driftDoc = `{
+	"basePath": "/api/v1",
+	"paths": {
+		"/accounts": {"get": {}},
+		"/accounts/{id}": {
+			"get": {"parameters": [{"name": "id", "in": "path", "required": true, "type": "integer"}]},
+			"delete": {"parameters": [{"name": "id", "in": "path", "required": true, "type": "integer"}]}
+		},
+		"/bottles/{id}": {"get": {"parameters": [{"name": "id", "in": "path", "required": true, "type": "integer"}]}}
+	}
+}`
+
+func TestDrift(t *testing.T) {
+	d, err := Parse([]byte(driftDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := func(echo.Context) error { return nil }
+	e := echo.New()
+	e.GET("/swagger/*", h)
+	mw := func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	// Group middleware routes every method of the group prefix and its
+	// subpaths to a fallback, which the GET of /api/v1/accounts replaces.
+	v1 := e.Group("/api/v1", mw)
+	accounts := v1.Group("/accounts", mw)
+	accounts.GET("", h)
+	accounts.GET("/:account_id", h)
+	v1.GET("/bottles/:id", h)
+	v1.POST("/bottles", h)
+
+	got := d.Drift(e.Routes())
+	want := &Drift{
+		Missing: []Endpoint{{Method: "POST", Path: "/bottles"}},
+		Extra:   []Endpoint{{Method: "DELETE", Path: "/accounts/{id}"}},
+		Mismatched: []Mismatch{{
+			Method: "GET",
+			Route:  "/accounts/:account_id",
+			Spec:   "/accounts/{id}",
+			Reason: "path parameter 1 is account_id in the route and id in the spec",
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("drift = %s, want %s", got, want)
+	}
+	if got.Empty() || got.Err() == nil {
+		t.Error("drift is empty")
+	}
+}
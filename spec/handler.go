@@ -0,0 +1,113 @@
+package spec
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// MIMEApplicationYAML is the media type of YAML documents.
+const MIMEApplicationYAML = "application/yaml"
+
+// Headers set by reverse proxies that echo does not name.
+const (
+	HeaderXForwardedHost = "X-Forwarded-Host"
+	// HeaderXForwardedPrefix is the path prefix the proxy mounts the server at.
+	HeaderXForwardedPrefix = "X-Forwarded-Prefix"
+)
+
+// Handler serves a swagger document as seen by the client of each request:
+// host, schemes and basePath are taken from the request and its
+// X-Forwarded-Host, X-Forwarded-Proto and X-Forwarded-Prefix headers, and
+// the OAuth2 URLs pointing at the documented host are moved along.
+type Handler struct {
+	doc      map[string]interface{}
+	host     string
+	basePath string
+}
+
+// NewHandler returns a Handler serving doc.
+func NewHandler(doc []byte) (*Handler, error) {
+	h := &Handler{}
+	if err := json.Unmarshal(doc, &h.doc); err != nil {
+		return nil, err
+	}
+	h.host, _ = h.doc["host"].(string)
+	h.basePath, _ = h.doc["basePath"].(string)
+	return h, nil
+}
+
+// Localize returns the document as seen by the client of the request.
+func (h *Handler) Localize(ctx echo.Context) map[string]interface{} {
+	req := ctx.Request()
+	host := req.Host
+	if fwd := req.Header.Get(HeaderXForwardedHost); fwd != "" {
+		host = firstValue(fwd)
+	}
+	scheme := firstValue(ctx.Scheme())
+	prefix := strings.TrimSuffix(req.Header.Get(HeaderXForwardedPrefix), "/")
+
+	doc := make(map[string]interface{}, len(h.doc))
+	for k, v := range h.doc {
+		doc[k] = v
+	}
+	doc["host"] = host
+	doc["schemes"] = []string{scheme}
+	doc["basePath"] = prefix + h.basePath
+	if defs, ok := h.doc["securityDefinitions"].(map[string]interface{}); ok {
+		localized := make(map[string]interface{}, len(defs))
+		for name, def := range defs {
+			d, ok := def.(map[string]interface{})
+			if !ok {
+				localized[name] = def
+				continue
+			}
+			c := make(map[string]interface{}, len(d))
+			for k, v := range d {
+				c[k] = v
+			}
+			for _, k := range []string{"authorizationUrl", "tokenUrl"} {
+				if s, ok := c[k].(string); ok {
+					c[k] = h.move(s, scheme, host, prefix)
+				}
+			}
+			localized[name] = c
+		}
+		doc["securityDefinitions"] = localized
+	}
+	return doc
+}
+
+// move rebases a URL on the documented host to the origin of the request.
+func (h *Handler) move(s, scheme, host, prefix string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Host != h.host {
+		return s
+	}
+	u.Scheme, u.Host, u.Path = scheme, host, prefix+u.Path
+	return u.String()
+}
+
+// JSON serves the document as JSON, e.g. at /swagger/doc.json for the UI.
+func (h *Handler) JSON(ctx echo.Context) error {
+	return ctx.JSONPretty(http.StatusOK, h.Localize(ctx), "    ")
+}
+
+// YAML serves the document as YAML.
+func (h *Handler) YAML(ctx echo.Context) error {
+	b, err := yaml.Marshal(h.Localize(ctx))
+	if err != nil {
+		return err
+	}
+	return ctx.Blob(http.StatusOK, MIMEApplicationYAML, b)
+}
+
+// firstValue is the value of the client-facing proxy in a header a chain
+// of proxies appends to.
+func firstValue(s string) string {
+	return strings.TrimSpace(strings.Split(s, ",")[0])
+}
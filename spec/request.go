@@ -0,0 +1,137 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/labstack/echo"
+)
+
+// ValidateRequests checks the path, query, header and JSON body parameters
+// of every request against the operation the document declares for the
+// matched route. Missing query and header parameters and body properties
+// with a default get it. A request breaking any rule is refused with a 400
+// httputil.Problem listing each parameter and the rule it broke. formData
+// parameters are left to the handlers, which limit upload sizes. Routes the
+// document does not describe are not checked.
+func ValidateRequests(doc *Document) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			op := doc.Operation(ctx.Request().Method, ctx.Path())
+			if op == nil {
+				return next(ctx)
+			}
+			c := &checker{doc: doc}
+			for _, p := range op.Parameters {
+				if err := c.parameter(ctx, p); err != nil {
+					return err
+				}
+			}
+			if len(c.errs) > 0 {
+				p := httputil.NewProblem(http.StatusBadRequest, "request does not match the API spec")
+				p.Errors = c.errs
+				return p
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// parameter checks p of the request and fills in its default.
+func (c *checker) parameter(ctx echo.Context, p *Parameter) error {
+	req := ctx.Request()
+	l := location{in: p.In, name: p.Name}
+	var values []string
+	switch p.In {
+	case "path":
+		values = []string{ctx.Param(p.Name)}
+	case "query":
+		values = ctx.QueryParams()[p.Name]
+	case "header":
+		values = req.Header[http.CanonicalHeaderKey(p.Name)]
+	case "body":
+		return c.body(ctx, p)
+	default:
+		return nil
+	}
+	if len(values) == 0 || values[0] == "" {
+		switch {
+		case p.Schema.Default != nil && p.In == "query":
+			q := ctx.QueryParams()
+			q.Set(p.Name, defaultString(p.Schema.Default))
+			req.URL.RawQuery = q.Encode()
+		case p.Schema.Default != nil && p.In == "header":
+			req.Header.Set(p.Name, defaultString(p.Schema.Default))
+		case p.Required:
+			l.fail(&c.errs, "required", "is required")
+		}
+		return nil
+	}
+	if p.Schema.Type == "array" {
+		items := split(p.CollectionFormat, values)
+		a := make([]interface{}, len(items))
+		for i, item := range items {
+			a[i] = item
+			if p.Schema.Items != nil {
+				a[i] = parse(p.Schema.Items, item)
+			}
+		}
+		c.check(p.Schema, a, l)
+		return nil
+	}
+	c.check(p.Schema, parse(p.Schema, values[0]), l)
+	return nil
+}
+
+// body checks a JSON body parameter. The body is left for the handler,
+// with the defaults of missing properties filled in.
+func (c *checker) body(ctx echo.Context, p *Parameter) error {
+	req := ctx.Request()
+	l := location{in: "body"}
+	if !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		if req.ContentLength == 0 && p.Required {
+			l.fail(&c.errs, "required", "is required")
+		}
+		return nil
+	}
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if len(bytes.TrimSpace(b)) == 0 {
+		if p.Required {
+			l.fail(&c.errs, "required", "is required")
+		}
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return httputil.NewProblem(http.StatusBadRequest, "request body is not valid JSON: "+err.Error())
+	}
+	c.check(p.Schema, v, l)
+	if c.defaulted {
+		if b, err = json.Marshal(v); err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		req.ContentLength = int64(len(b))
+		req.Header.Set(echo.HeaderContentLength, strconv.Itoa(len(b)))
+	}
+	return nil
+}
+
+func defaultString(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,187 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hexaforce/swagger-echo/httputil"
+	"github.com/hexaforce/swagger-echo/validate"
+	"github.com/labstack/echo"
+)
+
+// ResponseMode decides what happens to a response that breaks the spec.
+type ResponseMode string
+
+// Response modes.
+const (
+	// ResponseLog logs the violations and sends the response as it is,
+	// for development.
+	ResponseLog ResponseMode = "log"
+	// ResponseFail logs the violations and replaces the response by a 500
+	// httputil.Problem listing them, for tests.
+	ResponseFail ResponseMode = "fail"
+)
+
+// ParseResponseMode parses log or fail.
+func ParseResponseMode(s string) (ResponseMode, error) {
+	switch m := ResponseMode(s); m {
+	case ResponseLog, ResponseFail:
+		return m, nil
+	}
+	return "", errors.New("response mode must be one of log, fail")
+}
+
+// ValidateResponses buffers every response and checks its status code,
+// content type and body against the responses the document declares for
+// the matched route. JSON media types satisfy an operation producing
+// application/json and the problems of the error handler any operation;
+// file bodies are not checked. Errors of the handler are answered by the
+// HTTP error handler inside the middleware, so error responses are checked
+// too. Routes the document does not describe are not checked.
+func ValidateResponses(doc *Document, mode ResponseMode) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			op := doc.Operation(req.Method, ctx.Path())
+			if op == nil {
+				return next(ctx)
+			}
+			res := ctx.Response()
+			w := res.Writer
+			rec := &recorder{ResponseWriter: w}
+			res.Writer = rec
+			if err := next(ctx); err != nil {
+				ctx.Error(err)
+			}
+			res.Writer = w
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			errs := doc.checkResponse(op, req.Method, status, w.Header().Get(echo.HeaderContentType), rec.body.Bytes())
+			if len(errs) > 0 {
+				ctx.Logger().Errorf("response %d of %s %s does not match the API spec: %v", status, req.Method, op.Path, errs)
+				if mode == ResponseFail {
+					p := httputil.NewProblem(http.StatusInternalServerError, "response does not match the API spec")
+					p.Instance = req.URL.Path
+					p.RequestID = w.Header().Get(echo.HeaderXRequestID)
+					p.Errors = errs
+					b, err := json.Marshal(p)
+					if err != nil {
+						return err
+					}
+					w.Header().Set(echo.HeaderContentType, httputil.MIMEProblemJSON)
+					w.Header().Del(echo.HeaderContentLength)
+					res.Status, res.Size = p.Status, int64(len(b))
+					w.WriteHeader(p.Status)
+					_, err = w.Write(b)
+					return err
+				}
+			}
+			w.WriteHeader(status)
+			if rec.body.Len() > 0 && status != http.StatusNoContent && status != http.StatusNotModified {
+				_, err := w.Write(rec.body.Bytes())
+				return err
+			}
+			return nil
+		}
+	}
+}
+
+// recorder buffers a response.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
+
+// Flush does nothing, the response is sent once it is checked.
+func (r *recorder) Flush() {}
+
+// checkResponse checks a response of op against the document.
+func (d *Document) checkResponse(op *Operation, method string, status int, contentType string, body []byte) validate.Errors {
+	c := &checker{doc: d}
+	l := location{in: "response"}
+	r, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		r, ok = op.Responses["default"]
+	}
+	if !ok {
+		return validate.Errors{{In: l.in, Rule: "status", Message: fmt.Sprintf("response status %d is not declared", status)}}
+	}
+	if method == http.MethodHead {
+		return nil
+	}
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		if len(body) > 0 {
+			l.fail(&c.errs, "body", "must be empty for status %d", status)
+		}
+		return c.errs
+	}
+	s := c.resolve(r.Schema)
+	if s != nil && s.Type == "file" {
+		return nil
+	}
+	if len(body) == 0 {
+		if s != nil && s.Type != "string" {
+			l.fail(&c.errs, "body", "is empty")
+		}
+		return c.errs
+	}
+	if s == nil {
+		l.fail(&c.errs, "body", "must be empty, no schema is declared")
+		return c.errs
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	problem := mediaType == httputil.MIMEProblemJSON && status >= http.StatusBadRequest
+	if len(op.Produces) > 0 && !problem && !produces(op.Produces, mediaType) {
+		return validate.Errors{{In: l.in, Rule: "content-type", Message: fmt.Sprintf("response content type %q is not one of %s", contentType, strings.Join(op.Produces, ", "))}}
+	}
+	switch {
+	case isJSON(mediaType):
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			l.fail(&c.errs, "type", "is not valid JSON: %v", err)
+			return c.errs
+		}
+		c.check(s, v, l)
+	default:
+		c.check(s, parse(s, string(body)), l)
+	}
+	return c.errs
+}
+
+func produces(mediaTypes []string, mediaType string) bool {
+	for _, m := range mediaTypes {
+		if m == mediaType || m == "*/*" || (m == echo.MIMEApplicationJSON && isJSON(mediaType)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
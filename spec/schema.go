@@ -0,0 +1,299 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hexaforce/swagger-echo/validate"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Schema is the subset of a swagger 2.0 schema swag generates.
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Enum       []interface{}      `json:"enum"`
+	Default    interface{}        `json:"default"`
+	Example    interface{}        `json:"example"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+	Pattern    string             `json:"pattern"`
+	MinItems   *int               `json:"minItems"`
+	MaxItems   *int               `json:"maxItems"`
+	Items      *Schema            `json:"items"`
+	Required   []string           `json:"required"`
+	Properties map[string]*Schema `json:"properties"`
+}
+
+// location is where a checked value is: in a parameter, the request body
+// or the response body.
+type location struct {
+	in      string
+	name    string
+	pointer string
+}
+
+func (l location) at(token string) location {
+	token = strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+	return location{in: l.in, name: l.name, pointer: l.pointer + "/" + token}
+}
+
+func (l location) label() string {
+	path := strings.Replace(strings.TrimPrefix(l.pointer, "/"), "/", ".", -1)
+	switch {
+	case l.in == "body" && path == "":
+		return "body"
+	case l.in == "body":
+		return path
+	case l.in == "response" && path == "":
+		return "response body"
+	case l.in == "response":
+		return "response field " + path
+	}
+	label := l.in + " parameter " + l.name
+	if path != "" {
+		label += "." + path
+	}
+	return label
+}
+
+func (l location) fail(errs *validate.Errors, rule, format string, args ...interface{}) {
+	fe := validate.FieldError{In: l.in, Pointer: l.pointer, Rule: rule, Message: l.label() + " " + fmt.Sprintf(format, args...)}
+	fe.Parameter = l.name
+	*errs = append(*errs, fe)
+}
+
+// checker checks values against the schemas of a document.
+type checker struct {
+	doc  *Document
+	errs validate.Errors
+	// defaulted reports whether a default was filled into an object.
+	defaulted bool
+}
+
+// resolve follows the references of s to the definitions.
+func (c *checker) resolve(s *Schema) *Schema {
+	return c.doc.Resolve(s)
+}
+
+// check checks a value decoded with json.Decoder.UseNumber against s.
+// Missing object properties with a default are filled in.
+func (c *checker) check(s *Schema, v interface{}, l location) {
+	s = c.resolve(s)
+	if s == nil || v == nil {
+		return
+	}
+	typ := s.Type
+	if typ == "" && s.Properties != nil {
+		typ = "object"
+	}
+	switch typ {
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			l.fail(&c.errs, "type", "must be %s", article(typ))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := m[name]; !ok {
+				l.at(name).fail(&c.errs, "required", "is required")
+			}
+		}
+		for name, p := range s.Properties {
+			pv, ok := m[name]
+			if !ok {
+				if p := c.resolve(p); p != nil && p.Default != nil {
+					m[name] = p.Default
+					c.defaulted = true
+				}
+				continue
+			}
+			c.check(p, pv, l.at(name))
+		}
+	case "array":
+		a, ok := v.([]interface{})
+		if !ok {
+			l.fail(&c.errs, "type", "must be %s", article(typ))
+			return
+		}
+		if s.MinItems != nil && len(a) < *s.MinItems {
+			l.fail(&c.errs, "minItems", "must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(a) > *s.MaxItems {
+			l.fail(&c.errs, "maxItems", "must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range a {
+			c.check(s.Items, item, l.at(strconv.Itoa(i)))
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			l.fail(&c.errs, "type", "must be %s", article(typ))
+			return
+		}
+		n := utf8.RuneCountInString(str)
+		if s.MinLength != nil && n < *s.MinLength {
+			l.fail(&c.errs, "minLength", "must be at least %d characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			l.fail(&c.errs, "maxLength", "must be at most %d characters long", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			re, err := compile(s.Pattern)
+			if err == nil && !re.MatchString(str) {
+				l.fail(&c.errs, "pattern", "must match %s", s.Pattern)
+			}
+		}
+		if !validFormat(s.Format, str) {
+			l.fail(&c.errs, "format", "must be a valid %s", s.Format)
+		}
+	case "integer", "number":
+		num, ok := v.(json.Number)
+		if !ok {
+			l.fail(&c.errs, "type", "must be %s", article(typ))
+			return
+		}
+		f, err := num.Float64()
+		if typ == "integer" {
+			var i int64
+			i, err = num.Int64()
+			if err == nil && s.Format == "int32" && (i < math.MinInt32 || i > math.MaxInt32) {
+				l.fail(&c.errs, "format", "must be an int32")
+			}
+		}
+		if err != nil {
+			l.fail(&c.errs, "type", "must be %s", article(typ))
+			return
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			l.fail(&c.errs, "minimum", "must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			l.fail(&c.errs, "maximum", "must be at most %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			l.fail(&c.errs, "type", "must be %s", article(typ))
+			return
+		}
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		values := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			values[i] = fmt.Sprint(e)
+		}
+		l.fail(&c.errs, "enum", "must be one of %s", strings.Join(values, ", "))
+	}
+}
+
+// parse converts a parameter value to the JSON value its schema types.
+// Values that do not convert are left as strings and fail the type check.
+func parse(s *Schema, raw string) interface{} {
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// split splits an array parameter by its collection format.
+func split(format string, values []string) []string {
+	sep := ","
+	switch format {
+	case "multi":
+		return values
+	case "ssv":
+		sep = " "
+	case "tsv":
+		sep = "\t"
+	case "pipes":
+		sep = "|"
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return strings.Split(values[0], sep)
+}
+
+func inEnum(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if n, ok := v.(json.Number); ok {
+			f, err := n.Float64()
+			if ef, eok := e.(float64); eok && err == nil && f == ef {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// validFormat checks the string formats swag emits; others are not checked.
+func validFormat(format, s string) bool {
+	switch format {
+	case "uuid":
+		_, err := uuid.FromString(s)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	}
+	return true
+}
+
+func article(typ string) string {
+	if strings.IndexByte("aeiou", typ[0]) >= 0 {
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+// patterns caches compiled patterns.
+var patterns struct {
+	sync.Mutex
+	m map[string]*regexp.Regexp
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	patterns.Lock()
+	defer patterns.Unlock()
+	if re, ok := patterns.m[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if patterns.m == nil {
+		patterns.m = map[string]*regexp.Regexp{}
+	}
+	patterns.m[pattern] = re
+	return re, nil
+}
@@ -0,0 +1,169 @@
+package spec
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/swaggo/swag"
+)
+
+// Document is the part of a swagger 2.0 document requests and responses
+// are checked against.
+type Document struct {
+	BasePath            string                    `json:"basePath"`
+	Definitions         map[string]*Schema        `json:"definitions"`
+	SecurityDefinitions map[string]SecurityScheme `json:"securityDefinitions"`
+	// operations maps "METHOD echo-path" to the operation.
+	operations map[string]*Operation
+}
+
+// Operation is an operation of the document.
+type Operation struct {
+	Method     string       `json:"-"`
+	Path       string       `json:"-"`
+	Consumes   []string     `json:"consumes"`
+	Produces   []string     `json:"produces"`
+	Parameters []*Parameter `json:"parameters"`
+	// Responses maps status codes and default to the response.
+	Responses map[string]*Response `json:"responses"`
+	// Security lists the requirements any one of which grants access.
+	Security []SecurityRequirement `json:"security"`
+}
+
+// SecurityScheme is a security definition of the document.
+type SecurityScheme struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	In   string `json:"in"`
+}
+
+// SecurityRequirement is one security requirement of an operation: every
+// named scheme must be satisfied, OAuth2 schemes with all the listed scopes.
+type SecurityRequirement map[string][]string
+
+// Response is a response of an operation.
+type Response struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Parameter is a parameter of an operation.
+type Parameter struct {
+	Name     string
+	In       string
+	Required bool
+	// Schema is the schema of a body parameter or, for the others, the
+	// type and constraints declared on the parameter itself.
+	Schema *Schema
+	// CollectionFormat separates the values of array parameters.
+	CollectionFormat string
+}
+
+// UnmarshalJSON reads the schema of non-body parameters from the parameter.
+func (p *Parameter) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Name             string  `json:"name"`
+		In               string  `json:"in"`
+		Required         bool    `json:"required"`
+		Schema           *Schema `json:"schema"`
+		CollectionFormat string  `json:"collectionFormat"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	p.Name, p.In, p.Required, p.Schema, p.CollectionFormat = v.Name, v.In, v.Required, v.Schema, v.CollectionFormat
+	if p.In == "body" {
+		return nil
+	}
+	// required of a parameter is a boolean, not the required properties.
+	var s struct {
+		Schema
+		Required bool `json:"required"`
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	p.Schema = &s.Schema
+	return nil
+}
+
+// Read returns the document in file, or the document registered with swag
+// when file is empty.
+func Read(file string) ([]byte, error) {
+	if file == "" {
+		doc, err := swag.ReadDoc()
+		return []byte(doc), err
+	}
+	return ioutil.ReadFile(file)
+}
+
+// Parse reads the operations and definitions of a swagger 2.0 document.
+func Parse(doc []byte) (*Document, error) {
+	var d struct {
+		Document
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(doc, &d); err != nil {
+		return nil, err
+	}
+	d.operations = map[string]*Operation{}
+	for path, ops := range d.Paths {
+		for method, raw := range ops {
+			if method == "parameters" {
+				continue
+			}
+			var op Operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, err
+			}
+			op.Method, op.Path = strings.ToUpper(method), path
+			d.operations[op.Method+" "+EchoPath(d.BasePath, path)] = &op
+		}
+	}
+	return &d.Document, nil
+}
+
+// EchoPath converts a swagger path template such as /accounts/{id} below
+// basePath to the echo route path /basePath/accounts/:id.
+func EchoPath(basePath, path string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			segs[i] = ":" + seg[1:len(seg)-1]
+		}
+	}
+	return strings.TrimSuffix(basePath, "/") + strings.Join(segs, "/")
+}
+
+// Operations returns the operations of the document ordered by path and
+// method.
+func (d *Document) Operations() []*Operation {
+	ops := make([]*Operation, 0, len(d.operations))
+	for _, op := range d.operations {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+	return ops
+}
+
+// Resolve follows the references of s to their definition, nil when one
+// is not defined.
+func (d *Document) Resolve(s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		s = d.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+	}
+	return s
+}
+
+// Operation returns the operation echo routed method and path to, nil for
+// routes the document does not describe.
+func (d *Document) Operation(method, path string) *Operation {
+	return d.operations[method+" "+path]
+}
@@ -0,0 +1,44 @@
+package spec
+
+import "testing"
+
+const testDoc = `{
+	"basePath": "/api/v1",
+	"securityDefinitions": {"ApiKeyAuth": {"type": "apiKey", "name": "Authorization", "in": "header"}},
+	"paths": {
+		"/accounts/{id}": {
+			"parameters": [],
+			"get": {"security": [{"ApiKeyAuth": []}, {"OAuth2Application": ["read"]}]},
+			"delete": {}
+		}
+	}
+}`
+
+func TestEchoPath(t *testing.T) {
+	for _, tc := range []struct{ basePath, path, want string }{
+		{"/api/v1", "/accounts/{id}", "/api/v1/accounts/:id"},
+		{"/api/v1/", "/accounts/{id}/images/{image_id}", "/api/v1/accounts/:id/images/:image_id"},
+		{"", "/accounts", "/accounts"},
+	} {
+		if got := EchoPath(tc.basePath, tc.path); got != tc.want {
+			t.Errorf("EchoPath(%q, %q) = %q, want %q", tc.basePath, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestParseSecurity(t *testing.T) {
+	d, err := Parse([]byte(testDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := d.SecurityDefinitions["ApiKeyAuth"]; s.Type != "apiKey" || s.In != "header" {
+		t.Errorf("ApiKeyAuth = %+v", s)
+	}
+	op := d.Operation("GET", "/api/v1/accounts/:id")
+	if op == nil || len(op.Security) != 2 || len(op.Security[1]["OAuth2Application"]) != 1 {
+		t.Fatalf("GET security = %+v", op)
+	}
+	if op := d.Operation("DELETE", "/api/v1/accounts/:id"); op == nil || op.Security != nil {
+		t.Errorf("DELETE security = %+v, want none", op)
+	}
+}
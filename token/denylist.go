@@ -0,0 +1,68 @@
+package token
+
+import (
+	"sync"
+	"time"
+)
+
+// Denylist holds the IDs (jti) of revoked tokens until they expire.
+type Denylist interface {
+	Revoke(jti string, until time.Time) error
+	Revoked(jti string) (bool, error)
+	// RevokeOnce denies jti like Revoke unless it is already denied, and
+	// reports whether it was this call that revoked it. The check and the
+	// revocation are atomic, so only one of concurrent calls wins.
+	RevokeOnce(jti string, until time.Time) (bool, error)
+}
+
+// MemoryDenylist keeps revoked token IDs in process memory.
+// It is safe for concurrent use.
+type MemoryDenylist struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	now     func() time.Time
+}
+
+// NewMemoryDenylist returns an empty MemoryDenylist.
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{revoked: map[string]time.Time{}, now: time.Now}
+}
+
+// Revoke denies jti until the given time. Entries past their time are dropped.
+func (d *MemoryDenylist) Revoke(jti string, until time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.purge()
+	d.revoked[jti] = until
+	return nil
+}
+
+// RevokeOnce denies jti until the given time unless it is already denied.
+func (d *MemoryDenylist) RevokeOnce(jti string, until time.Time) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.purge()
+	if _, ok := d.revoked[jti]; ok {
+		return false, nil
+	}
+	d.revoked[jti] = until
+	return true, nil
+}
+
+// purge drops the entries past their time. d.mu must be held.
+func (d *MemoryDenylist) purge() {
+	now := d.now()
+	for k, v := range d.revoked {
+		if !now.Before(v) {
+			delete(d.revoked, k)
+		}
+	}
+}
+
+// Revoked reports whether jti is denied.
+func (d *MemoryDenylist) Revoked(jti string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	until, ok := d.revoked[jti]
+	return ok && d.now().Before(until), nil
+}
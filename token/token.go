@@ -0,0 +1,298 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/hexaforce/swagger-echo/model"
+)
+
+// Defaults for the lifetime of issued tokens and the tolerated clock skew.
+const (
+	DefaultTTL        = 15 * time.Minute
+	DefaultRefreshTTL = 7 * 24 * time.Hour
+	DefaultSkew       = 30 * time.Second
+)
+
+// Uses of a token, kept in the token_use claim.
+const (
+	UseAccess  = "access"
+	UseRefresh = "refresh"
+)
+
+var (
+	// ErrInvalid is returned for tokens that are malformed, wrongly signed or of the wrong use.
+	ErrInvalid error = &statusError{"token is invalid", http.StatusUnauthorized}
+	// ErrExpired is returned for tokens past their expiry.
+	ErrExpired error = &statusError{"token is expired", http.StatusUnauthorized}
+	// ErrNotYetValid is returned for tokens used before their not-before time.
+	ErrNotYetValid error = &statusError{"token is not valid yet", http.StatusUnauthorized}
+	// ErrRevoked is returned for tokens on the denylist.
+	ErrRevoked error = &statusError{"token is revoked", http.StatusUnauthorized}
+)
+
+// statusError is an error answered with the HTTP status it carries.
+type statusError struct {
+	msg    string
+	status int
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// Status is the HTTP status the error is answered with.
+func (e *statusError) Status() int {
+	return e.status
+}
+
+// Claims are the claims of the tokens issued by a Manager.
+type Claims struct {
+	jwt.StandardClaims
+	AdminID int      `json:"admin_id,omitempty"`
+	Roles   []string `json:"roles,omitempty"`
+	Scopes  []string `json:"scopes,omitempty"`
+	Use     string   `json:"token_use"`
+	// KeyID and KeyPrefix name the API key the token was issued for.
+	KeyID     string `json:"key_id,omitempty"`
+	KeyPrefix string `json:"key_prefix,omitempty"`
+}
+
+// Admin is the admin the claims were issued for.
+func (c Claims) Admin() model.Admin {
+	return model.Admin{ID: c.AdminID, Name: c.Subject, Roles: c.Roles, Scopes: c.Scopes, KeyID: c.KeyID, KeyPrefix: c.KeyPrefix}
+}
+
+// KeyStore tells whether the API key a token was issued for is still
+// valid: not revoked, expired or rotated to a key with another prefix.
+type KeyStore interface {
+	APIKeyValid(id, prefix string) (bool, error)
+}
+
+// Manager issues, verifies, refreshes and revokes signed JWTs.
+// It is safe for concurrent use once configured.
+type Manager struct {
+	// Issuer is the iss claim of issued tokens.
+	Issuer string
+	// TTL is the lifetime of access tokens.
+	TTL time.Duration
+	// RefreshTTL is the lifetime of refresh tokens.
+	RefreshTTL time.Duration
+	// Skew is tolerated between the clocks of the issuer and the verifier.
+	Skew time.Duration
+	// Denylist holds revoked tokens.
+	Denylist Denylist
+	// Keys checks the API key of tokens issued for one, so that they die
+	// with the key. Such tokens are not checked when nil.
+	Keys KeyStore
+
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	now       func() time.Time
+}
+
+func newManager(method jwt.SigningMethod, signKey, verifyKey interface{}) *Manager {
+	return &Manager{
+		Issuer:     "celler",
+		TTL:        DefaultTTL,
+		RefreshTTL: DefaultRefreshTTL,
+		Skew:       DefaultSkew,
+		Denylist:   NewMemoryDenylist(),
+		method:     method,
+		signKey:    signKey,
+		verifyKey:  verifyKey,
+		now:        time.Now,
+	}
+}
+
+// NewHS256 returns a Manager signing with HMAC SHA-256 and secret.
+func NewHS256(secret []byte) *Manager {
+	return newManager(jwt.SigningMethodHS256, secret, secret)
+}
+
+// NewRS256 returns a Manager signing with RSA SHA-256 and key.
+func NewRS256(key *rsa.PrivateKey) *Manager {
+	return newManager(jwt.SigningMethodRS256, key, &key.PublicKey)
+}
+
+// Load returns a Manager for alg HS256 or RS256 with the key in file:
+// the raw secret for HS256, a PEM encoded RSA private key for RS256.
+// Without a file HS256 uses a random secret, so tokens do not survive a restart.
+func Load(alg, file string) (*Manager, error) {
+	var b []byte
+	if file != "" {
+		var err error
+		if b, err = ioutil.ReadFile(file); err != nil {
+			return nil, err
+		}
+	}
+	switch alg {
+	case "HS256":
+		if file == "" {
+			b = make([]byte, 32)
+			if _, err := rand.Read(b); err != nil {
+				return nil, err
+			}
+		}
+		if len(b) < 32 {
+			return nil, errors.New("HS256 secret must be at least 32 bytes")
+		}
+		return NewHS256(b), nil
+	case "RS256":
+		if file == "" {
+			return nil, errors.New("RS256 needs a private key file")
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(b)
+		if err != nil {
+			return nil, err
+		}
+		return NewRS256(key), nil
+	}
+	return nil, fmt.Errorf("signing algorithm %s is not supported, use HS256 or RS256", alg)
+}
+
+// Issue returns a new access and refresh token pair for admin.
+func (m *Manager) Issue(admin model.Admin) (model.AdminToken, error) {
+	access, err := m.sign(admin, UseAccess, m.TTL)
+	if err != nil {
+		return model.AdminToken{}, err
+	}
+	refresh, err := m.sign(admin, UseRefresh, m.RefreshTTL)
+	if err != nil {
+		return model.AdminToken{}, err
+	}
+	return model.AdminToken{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "bearer",
+		ExpiresIn:    int(m.TTL / time.Second),
+		Admin:        admin,
+	}, nil
+}
+
+func (m *Manager) sign(admin model.Admin, use string, ttl time.Duration) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	now := m.now()
+	claims := Claims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        base64.RawURLEncoding.EncodeToString(jti),
+			Issuer:    m.Issuer,
+			Subject:   admin.Name,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+		},
+		AdminID:   admin.ID,
+		Roles:     admin.Roles,
+		Scopes:    admin.Scopes,
+		Use:       use,
+		KeyID:     admin.KeyID,
+		KeyPrefix: admin.KeyPrefix,
+	}
+	return jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+}
+
+// Verify checks the signature, issuer, lifetime and revocation of a token
+// of the given use and returns its claims. Expiry and not-before are
+// checked with m.Skew leeway. A token issued for an API key is revoked
+// once the key is.
+func (m *Manager) Verify(token, use string) (Claims, error) {
+	claims, err := m.parse(token)
+	if err != nil {
+		return Claims{}, err
+	}
+	now := m.now()
+	switch {
+	case claims.Use != use || claims.Issuer != m.Issuer || claims.Id == "":
+		return Claims{}, ErrInvalid
+	case now.After(time.Unix(claims.ExpiresAt, 0).Add(m.Skew)):
+		return Claims{}, ErrExpired
+	case now.Add(m.Skew).Before(time.Unix(claims.NotBefore, 0)):
+		return Claims{}, ErrNotYetValid
+	}
+	revoked, err := m.Denylist.Revoked(claims.Id)
+	if err != nil {
+		return Claims{}, err
+	}
+	if revoked {
+		return Claims{}, ErrRevoked
+	}
+	if claims.KeyID != "" && m.Keys != nil {
+		valid, err := m.Keys.APIKeyValid(claims.KeyID, claims.KeyPrefix)
+		if err != nil {
+			return Claims{}, err
+		}
+		if !valid {
+			return Claims{}, ErrRevoked
+		}
+	}
+	return claims, nil
+}
+
+// parse checks the signature of a token and decodes its claims.
+func (m *Manager) parse(token string) (Claims, error) {
+	var claims Claims
+	p := jwt.Parser{ValidMethods: []string{m.method.Alg()}, SkipClaimsValidation: true}
+	_, err := p.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return m.verifyKey, nil
+	})
+	if err != nil {
+		return Claims{}, ErrInvalid
+	}
+	return claims, nil
+}
+
+// AdminByToken implements auth.TokenStore for access tokens.
+func (m *Manager) AdminByToken(token string) (model.Admin, error) {
+	claims, err := m.Verify(token, UseAccess)
+	if err != nil {
+		return model.Admin{}, err
+	}
+	return claims.Admin(), nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The refresh
+// token is revoked so that it can be used only once, even by concurrent
+// refreshes: all but one of them get ErrRevoked.
+func (m *Manager) Refresh(refreshToken string) (model.AdminToken, error) {
+	claims, err := m.Verify(refreshToken, UseRefresh)
+	if err != nil {
+		return model.AdminToken{}, err
+	}
+	won, err := m.Denylist.RevokeOnce(claims.Id, time.Unix(claims.ExpiresAt, 0).Add(m.Skew))
+	if err != nil {
+		return model.AdminToken{}, err
+	}
+	if !won {
+		return model.AdminToken{}, ErrRevoked
+	}
+	return m.Issue(claims.Admin())
+}
+
+// Revoke puts a validly signed access or refresh token on the denylist
+// until it expires. Revoking an expired or revoked token is a no-op.
+func (m *Manager) Revoke(token string) error {
+	claims, err := m.parse(token)
+	if err != nil {
+		return err
+	}
+	if claims.Issuer != m.Issuer || claims.Id == "" {
+		return ErrInvalid
+	}
+	until := time.Unix(claims.ExpiresAt, 0).Add(m.Skew)
+	if !m.now().Before(until) {
+		return nil
+	}
+	return m.Denylist.Revoke(claims.Id, until)
+}
@@ -0,0 +1,104 @@
+package token
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hexaforce/swagger-echo/model"
+)
+
+// keys is a KeyStore of the valid key IDs and their prefixes.
+type keys map[string]string
+
+func (k keys) APIKeyValid(id, prefix string) (bool, error) {
+	p, ok := k[id]
+	return ok && p == prefix, nil
+}
+
+func TestTokensDieWithAPIKey(t *testing.T) {
+	m := NewHS256([]byte("0123456789abcdef0123456789abcdef"))
+	valid := keys{"k1": "cel_aaaaaa"}
+	m.Keys = valid
+
+	admin := model.Admin{ID: 1, Name: "ci", KeyID: "k1", KeyPrefix: "cel_aaaaaa"}
+	pair, err := m.Issue(admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.AdminByToken(pair.AccessToken); err != nil {
+		t.Fatalf("access token of a valid key: %v", err)
+	}
+
+	// Rotating the key changes its prefix.
+	valid["k1"] = "cel_bbbbbb"
+	if _, err := m.AdminByToken(pair.AccessToken); err != ErrRevoked {
+		t.Errorf("access token of a rotated key: %v, want %v", err, ErrRevoked)
+	}
+	if _, err := m.Refresh(pair.RefreshToken); err != ErrRevoked {
+		t.Errorf("refresh token of a rotated key: %v, want %v", err, ErrRevoked)
+	}
+
+	admin.KeyPrefix = "cel_bbbbbb"
+	if pair, err = m.Issue(admin); err != nil {
+		t.Fatal(err)
+	}
+	delete(valid, "k1")
+	if _, err := m.Refresh(pair.RefreshToken); err != ErrRevoked {
+		t.Errorf("refresh token of a revoked key: %v, want %v", err, ErrRevoked)
+	}
+
+	// Tokens of other credentials are not tied to a key.
+	if pair, err = m.Issue(model.Admin{ID: 1, Name: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Refresh(pair.RefreshToken); err != nil {
+		t.Errorf("refresh token without key: %v", err)
+	}
+}
+
+// barrier holds every Revoked call after its check until all of them are
+// done, so that concurrent refreshes all pass Verify before any revokes.
+type barrier struct {
+	*MemoryDenylist
+	wg sync.WaitGroup
+}
+
+func (b *barrier) Revoked(jti string) (bool, error) {
+	revoked, err := b.MemoryDenylist.Revoked(jti)
+	b.wg.Done()
+	b.wg.Wait()
+	return revoked, err
+}
+
+func TestConcurrentRefresh(t *testing.T) {
+	m := NewHS256([]byte("0123456789abcdef0123456789abcdef"))
+	pair, err := m.Issue(model.Admin{ID: 1, Name: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 8
+	b := &barrier{MemoryDenylist: NewMemoryDenylist()}
+	b.wg.Add(n)
+	m.Denylist = b
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := m.Refresh(pair.RefreshToken)
+			errs <- err
+		}()
+	}
+	won := 0
+	for i := 0; i < n; i++ {
+		switch err := <-errs; err {
+		case nil:
+			won++
+		case ErrRevoked:
+		default:
+			t.Errorf("Refresh: %v", err)
+		}
+	}
+	if won != 1 {
+		t.Errorf("%d of %d concurrent refreshes succeeded, want 1", won, n)
+	}
+}
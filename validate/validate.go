@@ -0,0 +1,290 @@
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/labstack/echo"
+)
+
+// FieldError example
+type FieldError struct {
+	// In and Parameter locate a failed parameter outside the body, see package spec.
+	In        string `json:"in,omitempty" example:"body"`
+	Parameter string `json:"parameter,omitempty" example:"limit"`
+	// Pointer is the RFC 6901 JSON pointer of the field in the request body.
+	Pointer string `json:"pointer,omitempty" example:"/name"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"name is required"`
+}
+
+// Errors lists every field that failed validation.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validator checks the constraints declared by struct tags, the subset of
+// the go-playground/validator syntax swag turns into schema constraints:
+//
+//	validate:"required,min=1,max=64,oneof=read write admin,email"
+//	pattern:"^[a-z]+$"
+//
+// min and max bound the length of strings and slices and the value of
+// numbers, oneof applies to strings and to every element of string slices.
+// Rules other than required skip zero values. Fields are named by their
+// json tag. When every field is valid, a Validation() error method of the
+// value is called for rules across fields.
+type Validator struct {
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+// New returns a Validator.
+func New() *Validator {
+	return &Validator{patterns: map[string]*regexp.Regexp{}}
+}
+
+// Validate implements echo.Validator. It returns Errors for failed field
+// constraints and the error of the Validation method otherwise.
+func (v *Validator) Validate(i interface{}) error {
+	var errs Errors
+	if err := v.walk(reflect.ValueOf(i), "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if val, ok := i.(interface{ Validation() error }); ok {
+		return val.Validation()
+	}
+	return nil
+}
+
+// Binder binds like echo.DefaultBinder and then validates the bound value
+// with the Validator of the echo instance.
+type Binder struct {
+	echo.DefaultBinder
+}
+
+// Bind implements echo.Binder.
+func (b *Binder) Bind(i interface{}, ctx echo.Context) error {
+	if err := b.DefaultBinder.Bind(i, ctx); err != nil {
+		return err
+	}
+	return ctx.Validate(i)
+}
+
+func (v *Validator) walk(rv reflect.Value, pointer string, errs *Errors) error {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := v.walk(rv.Index(i), pointer+"/"+strconv.Itoa(i), errs); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+	default:
+		return nil
+	}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, embedded := jsonName(f)
+		if name == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		fv := rv.Field(i)
+		if embedded {
+			if err := v.walk(fv, pointer, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		p := pointer + "/" + escape(name)
+		if err := v.check(f, fv, p, errs); err != nil {
+			return err
+		}
+		if err := v.walk(fv, p, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check applies the rules declared on field f with value fv.
+func (v *Validator) check(f reflect.StructField, fv reflect.Value, pointer string, errs *Errors) error {
+	label := strings.Replace(strings.TrimPrefix(pointer, "/"), "/", ".", -1)
+	fail := func(p, rule, format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Pointer: p, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+	var rules []string
+	if tag := f.Tag.Get("validate"); tag != "" {
+		rules = strings.Split(tag, ",")
+	}
+	zero := isZero(fv)
+	for _, rule := range rules {
+		if rule == "required" && zero {
+			fail(pointer, rule, "%s is required", label)
+			return nil
+		}
+	}
+	if zero {
+		return nil
+	}
+	val := fv
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	for _, rule := range rules {
+		kv := strings.SplitN(rule, "=", 2)
+		name, param := kv[0], ""
+		if len(kv) == 2 {
+			param = kv[1]
+		}
+		switch name {
+		case "required", "omitempty":
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return fmt.Errorf("validate: %s of field %s: %v", rule, f.Name, err)
+			}
+			size, unit, ok := measure(val)
+			if !ok {
+				return fmt.Errorf("validate: %s does not apply to field %s of kind %s", name, f.Name, val.Kind())
+			}
+			if name == "min" && size < n {
+				fail(pointer, name, "%s must be at least %v%s", label, n, unit)
+			}
+			if name == "max" && size > n {
+				fail(pointer, name, "%s must be at most %v%s", label, n, unit)
+			}
+		case "oneof":
+			allowed := strings.Fields(param)
+			check := func(p, s string) {
+				for _, a := range allowed {
+					if s == a {
+						return
+					}
+				}
+				fail(p, name, "%s must be one of %s", strings.Replace(strings.TrimPrefix(p, "/"), "/", ".", -1), strings.Join(allowed, ", "))
+			}
+			switch val.Kind() {
+			case reflect.String:
+				check(pointer, val.String())
+			case reflect.Slice, reflect.Array:
+				for i := 0; i < val.Len(); i++ {
+					if e := val.Index(i); e.Kind() == reflect.String {
+						check(pointer+"/"+strconv.Itoa(i), e.String())
+					}
+				}
+			default:
+				return fmt.Errorf("validate: oneof does not apply to field %s of kind %s", f.Name, val.Kind())
+			}
+		case "email":
+			s := val.String()
+			if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+				fail(pointer, name, "%s must be an email address", label)
+			}
+		default:
+			return fmt.Errorf("validate: rule %s of field %s is unknown", name, f.Name)
+		}
+	}
+	if pattern := f.Tag.Get("pattern"); pattern != "" && val.Kind() == reflect.String {
+		re, err := v.compile(pattern)
+		if err != nil {
+			return fmt.Errorf("validate: pattern of field %s: %v", f.Name, err)
+		}
+		if !re.MatchString(val.String()) {
+			fail(pointer, "pattern", "%s must match %s", label, pattern)
+		}
+	}
+	return nil
+}
+
+func (v *Validator) compile(pattern string) (*regexp.Regexp, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+// measure returns what min and max bound for val: the length of strings
+// and slices or the value of numbers.
+func measure(val reflect.Value) (float64, string, bool) {
+	switch val.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(val.String())), " characters long", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(val.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), "", true
+	}
+	return 0, "", false
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil() || (v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface && v.Len() == 0)
+	case reflect.String, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	}
+	return false
+}
+
+// jsonName returns the name encoding/json uses for f and whether f is an
+// embedded struct whose fields are promoted.
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	name := strings.Split(tag, ",")[0]
+	if name == "" && f.Anonymous {
+		return "", true
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, false
+}
+
+// escape escapes a JSON pointer reference token.
+func escape(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}